@@ -69,13 +69,22 @@ func main() {
 
 	// Initialize game engine
 	gameEngine := game.NewGameEngine(db, rdb, cfg)
+	weatherProvider, err := game.NewWeatherProvider(ctx, cfg, rdb)
+	if err != nil {
+		log.Fatalf("Failed to initialize weather provider: %v", err)
+	}
+	gameEngine.SetWeatherProvider(weatherProvider)
 	gameEngine.Start()
 	defer gameEngine.Stop()
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(db, jwtManager)
-	gardenHandler := handlers.NewGardenHandler(db)
+	authHandler := handlers.NewAuthHandler(db, jwtManager, gameEngine)
+	gardenHandler := handlers.NewGardenHandler(db, gameEngine)
 	weatherHandler := handlers.NewWeatherHandler(db, gameEngine)
+	gameHandler := handlers.NewGameHandler(db, gameEngine)
+	inventoryHandler := handlers.NewInventoryHandler(db, gameEngine)
+	harvestHandler := handlers.NewHarvestHandler(db)
+	wsHandler := handlers.NewWebSocketHandler(db, gameEngine, cfg)
 
 	// Initialize router
 	router := gin.Default()
@@ -87,11 +96,20 @@ func main() {
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"status":    "healthy",
 			"timestamp": time.Now().UTC(),
 			"version":   "1.0.0",
-		})
+		}
+
+		// Report which replica runs the game loops
+		leader, err := gameEngine.Leader()
+		if err != nil {
+			leader.LeaderID = "unknown"
+		}
+		response["game_leader"] = leader
+
+		c.JSON(http.StatusOK, response)
 	})
 
 	// Swagger documentation
@@ -115,6 +133,9 @@ func main() {
 		{
 			users.GET("/profile", authHandler.GetProfile)
 			users.PUT("/profile", authHandler.UpdateProfile)
+			users.GET("/ledger", authHandler.GetLedger)
+			users.GET("/harvests", harvestHandler.GetHarvests)
+			users.GET("/harvests/export", harvestHandler.ExportHarvests)
 		}
 
 		// Garden routes (protected)
@@ -127,40 +148,52 @@ func main() {
 			gardens.PUT("/:id", gardenHandler.UpdateGarden)
 			gardens.DELETE("/:id", gardenHandler.DeleteGarden)
 
+			// Upgrade routes
+			gardens.GET("/:id/upgrades", gardenHandler.ListUpgrades)
+			gardens.POST("/:id/upgrades", gardenHandler.PurchaseUpgrade)
+			gardens.POST("/:id/expand", gardenHandler.ExpandGarden)
+
 			// Plant routes
 			gardens.POST("/:id/plants", gardenHandler.PlantSeed)
 			gardens.PUT("/:id/plants/:plantId", gardenHandler.WaterPlant)
 			gardens.POST("/:id/plants/:plantId/fertilize", gardenHandler.FertilizePlant)
 			gardens.POST("/:id/plants/:plantId/harvest", gardenHandler.HarvestPlant)
+			gardens.POST("/:id/plants/:plantId/revive", gardenHandler.RevivePlant)
 			gardens.DELETE("/:id/plants/:plantId", gardenHandler.RemovePlant)
 		}
 
+		// Inventory routes (protected)
+		inventory := api.Group("/inventory")
+		inventory.Use(middleware.AuthMiddleware(jwtManager))
+		{
+			inventory.GET("", inventoryHandler.GetInventory)
+			inventory.POST("/:plantTypeId/sell", inventoryHandler.SellItems)
+			inventory.POST("/:plantTypeId/consume", inventoryHandler.ConsumeItems)
+		}
+
 		// Public routes
 		api.GET("/plants", gardenHandler.ListPlantTypes)
 
-		api.GET("/weather/current", weatherHandler.GetCurrentWeather)
-		api.GET("/weather/forecast", weatherHandler.GetWeatherForecast)
-		api.GET("/weather/history", weatherHandler.GetWeatherHistory)
+		// Weather lookups by signed-in players count towards achievements
+		weather := api.Group("/weather")
+		weather.Use(middleware.OptionalAuthMiddleware(jwtManager))
+		{
+			weather.GET("/current", weatherHandler.GetCurrentWeather)
+			weather.GET("/forecast", weatherHandler.GetWeatherForecast)
+			weather.GET("/history", weatherHandler.GetWeatherHistory)
+		}
 
-		api.GET("/game/status", func(c *gin.Context) {
-			// TODO: Implement game status endpoint
-			c.JSON(http.StatusOK, gin.H{"message": "Game status endpoint - coming soon"})
-		})
+		api.GET("/game/status", middleware.OptionalAuthMiddleware(jwtManager), gameHandler.GetStatus)
 
-		api.GET("/game/leaderboard", func(c *gin.Context) {
-			// TODO: Implement leaderboard endpoint
-			c.JSON(http.StatusOK, gin.H{"message": "Leaderboard endpoint - coming soon"})
-		})
+		api.GET("/game/leaderboard", gameHandler.GetLeaderboard)
+		api.GET("/game/leaderboard/me", middleware.AuthMiddleware(jwtManager), gameHandler.GetMyRank)
 	}
 
 	// WebSocket routes (protected)
 	ws := router.Group("/api/v1/ws")
-	ws.Use(middleware.AuthMiddleware(jwtManager))
+	ws.Use(middleware.WebSocketAuthMiddleware(jwtManager))
 	{
-		ws.GET("/garden/:gardenId", func(c *gin.Context) {
-			// TODO: Implement WebSocket handler for real-time garden updates
-			c.JSON(http.StatusOK, gin.H{"message": "WebSocket endpoint - coming soon"})
-		})
+		ws.GET("/garden/:gardenId", wsHandler.GardenUpdates)
 	}
 
 	// Create HTTP server
@@ -15,6 +15,7 @@ type Config struct {
 	Redis    RedisConfig
 	JWT      JWTConfig
 	Game     GameConfig
+	Weather  WeatherConfig
 	API      APIConfig
 }
 
@@ -48,6 +49,22 @@ type GameConfig struct {
 	TickInterval          time.Duration
 	WeatherUpdateInterval time.Duration
 	PlantGrowthInterval   time.Duration
+	LeaderLeaseTTL        time.Duration
+	WeatherTransitions    WeatherTransitions
+	ForecastHorizon       time.Duration
+	WeatherWarningWindow  time.Duration
+}
+
+// WeatherConfig selects where the weather comes from
+type WeatherConfig struct {
+	Provider    string // random, replay or http
+	ReplayFile  string // CSV or JSON weather records to replay
+	ReplayFrom  string // RFC 3339 time in the replay file to start from
+	ReplayStart string // RFC 3339 wall-clock time the replay started playing
+	APIURL      string // OpenWeather-style current weather endpoint
+	APIKey      string
+	Latitude    string
+	Longitude   string
 }
 
 type APIConfig struct {
@@ -63,6 +80,11 @@ func Load() (*Config, error) {
 		fmt.Println("No .env file found, using environment variables")
 	}
 
+	weatherTransitions, err := loadWeatherTransitions(getEnv("WEATHER_TRANSITIONS_FILE", ""))
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
 		Server: ServerConfig{
 			Port: getEnv("PORT", "8080"),
@@ -90,6 +112,20 @@ func Load() (*Config, error) {
 			TickInterval:          getEnvAsDuration("GAME_TICK_INTERVAL", 5*time.Minute),
 			WeatherUpdateInterval: getEnvAsDuration("WEATHER_UPDATE_INTERVAL", 10*time.Minute),
 			PlantGrowthInterval:   getEnvAsDuration("PLANT_GROWTH_INTERVAL", 15*time.Minute),
+			LeaderLeaseTTL:        getEnvAsDuration("GAME_LEADER_LEASE_TTL", 15*time.Second),
+			WeatherTransitions:    weatherTransitions,
+			ForecastHorizon:       getEnvAsDuration("WEATHER_FORECAST_HORIZON", 24*time.Hour),
+			WeatherWarningWindow:  getEnvAsDuration("WEATHER_WARNING_WINDOW", 6*time.Hour),
+		},
+		Weather: WeatherConfig{
+			Provider:    getEnv("WEATHER_PROVIDER", "random"),
+			ReplayFile:  getEnv("WEATHER_REPLAY_FILE", ""),
+			ReplayFrom:  getEnv("WEATHER_REPLAY_FROM", ""),
+			ReplayStart: getEnv("WEATHER_REPLAY_START", ""),
+			APIURL:      getEnv("WEATHER_API_URL", "https://api.openweathermap.org/data/2.5/weather"),
+			APIKey:      getEnv("WEATHER_API_KEY", ""),
+			Latitude:    getEnv("WEATHER_API_LAT", "51.5074"),
+			Longitude:   getEnv("WEATHER_API_LON", "-0.1278"),
 		},
 		API: APIConfig{
 			CORSOrigin:        getEnv("CORS_ORIGIN", "http://localhost:3000"),
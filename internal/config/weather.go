@@ -0,0 +1,104 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/my-garden/api/internal/models"
+)
+
+// WeatherTransitions drive the weather as a Markov chain. For each season it gives
+// the relative chance of the weather moving from one condition to each other condition
+// at a weather update. Weights in a row don't have to add up to 1.
+type WeatherTransitions map[models.Season]map[models.WeatherCondition]map[models.WeatherCondition]float64
+
+// DefaultWeatherTransitions favour keeping the current weather, so spells of rain or
+// sunshine last a few updates and the weather moves through plausible in-between states
+func DefaultWeatherTransitions() WeatherTransitions {
+	return WeatherTransitions{
+		models.SeasonSpring: {
+			models.WeatherSunny:  {models.WeatherSunny: 0.6, models.WeatherCloudy: 0.25, models.WeatherWindy: 0.1, models.WeatherRainy: 0.05},
+			models.WeatherCloudy: {models.WeatherCloudy: 0.45, models.WeatherSunny: 0.2, models.WeatherRainy: 0.2, models.WeatherWindy: 0.1, models.WeatherFoggy: 0.05},
+			models.WeatherRainy:  {models.WeatherRainy: 0.5, models.WeatherCloudy: 0.3, models.WeatherFoggy: 0.1, models.WeatherSunny: 0.1},
+			models.WeatherFoggy:  {models.WeatherFoggy: 0.4, models.WeatherCloudy: 0.4, models.WeatherSunny: 0.2},
+			models.WeatherWindy:  {models.WeatherWindy: 0.4, models.WeatherCloudy: 0.3, models.WeatherSunny: 0.2, models.WeatherRainy: 0.1},
+		},
+		models.SeasonSummer: {
+			models.WeatherSunny:  {models.WeatherSunny: 0.7, models.WeatherCloudy: 0.2, models.WeatherWindy: 0.07, models.WeatherStormy: 0.03},
+			models.WeatherCloudy: {models.WeatherCloudy: 0.4, models.WeatherSunny: 0.4, models.WeatherStormy: 0.1, models.WeatherWindy: 0.1},
+			models.WeatherStormy: {models.WeatherStormy: 0.3, models.WeatherCloudy: 0.5, models.WeatherWindy: 0.2},
+			models.WeatherWindy:  {models.WeatherWindy: 0.4, models.WeatherSunny: 0.3, models.WeatherCloudy: 0.2, models.WeatherStormy: 0.1},
+		},
+		models.SeasonAutumn: {
+			models.WeatherCloudy: {models.WeatherCloudy: 0.45, models.WeatherRainy: 0.25, models.WeatherFoggy: 0.1, models.WeatherWindy: 0.1, models.WeatherSunny: 0.1},
+			models.WeatherRainy:  {models.WeatherRainy: 0.5, models.WeatherCloudy: 0.3, models.WeatherWindy: 0.1, models.WeatherFoggy: 0.1},
+			models.WeatherFoggy:  {models.WeatherFoggy: 0.45, models.WeatherCloudy: 0.35, models.WeatherSunny: 0.1, models.WeatherRainy: 0.1},
+			models.WeatherWindy:  {models.WeatherWindy: 0.4, models.WeatherCloudy: 0.3, models.WeatherRainy: 0.2, models.WeatherSunny: 0.1},
+			models.WeatherSunny:  {models.WeatherSunny: 0.5, models.WeatherCloudy: 0.3, models.WeatherWindy: 0.1, models.WeatherFoggy: 0.1},
+		},
+		models.SeasonWinter: {
+			models.WeatherCloudy: {models.WeatherCloudy: 0.45, models.WeatherSnowy: 0.25, models.WeatherFoggy: 0.15, models.WeatherWindy: 0.15},
+			models.WeatherSnowy:  {models.WeatherSnowy: 0.55, models.WeatherCloudy: 0.3, models.WeatherWindy: 0.15},
+			models.WeatherFoggy:  {models.WeatherFoggy: 0.45, models.WeatherCloudy: 0.4, models.WeatherSnowy: 0.15},
+			models.WeatherWindy:  {models.WeatherWindy: 0.35, models.WeatherCloudy: 0.35, models.WeatherSnowy: 0.3},
+		},
+	}
+}
+
+// loadWeatherTransitions starts from the default transitions and, if a file is given,
+// replaces every row the file defines. The file is JSON shaped like
+// {"summer": {"sunny": {"sunny": 0.8, "cloudy": 0.2}}}.
+func loadWeatherTransitions(path string) (WeatherTransitions, error) {
+	transitions := DefaultWeatherTransitions()
+	// An empty value followed by a comment in .env reads as the comment
+	path = strings.TrimSpace(path)
+	if path == "" || strings.HasPrefix(path, "#") {
+		return transitions, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read weather transitions: %w", err)
+	}
+	var overrides WeatherTransitions
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse weather transitions %s: %w", path, err)
+	}
+
+	for season, rows := range overrides {
+		if _, ok := transitions[season]; !ok {
+			return nil, fmt.Errorf("weather transitions %s: unknown season %q", path, season)
+		}
+		for from, row := range rows {
+			if !from.Valid() {
+				return nil, fmt.Errorf("weather transitions %s: %s: unknown condition %q", path, season, from)
+			}
+			if err := validateTransitionRow(row); err != nil {
+				return nil, fmt.Errorf("weather transitions %s: %s from %s: %w", path, season, from, err)
+			}
+			transitions[season][from] = row
+		}
+	}
+
+	return transitions, nil
+}
+
+// validateTransitionRow checks that a row's weights can be drawn from
+func validateTransitionRow(row map[models.WeatherCondition]float64) error {
+	total := 0.0
+	for to, weight := range row {
+		if !to.Valid() {
+			return fmt.Errorf("unknown condition %q", to)
+		}
+		if weight < 0 {
+			return fmt.Errorf("negative weight for %s", to)
+		}
+		total += weight
+	}
+	if total <= 0 {
+		return fmt.Errorf("no weight on any condition")
+	}
+	return nil
+}
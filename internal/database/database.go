@@ -54,16 +54,142 @@ func NewDatabase(cfg *config.Config) (*Database, error) {
 func (d *Database) Migrate() error {
 	log.Println("Running database migrations...")
 
-	return d.DB.AutoMigrate(
+	if err := d.DB.AutoMigrate(
+		&Migration{},
 		&models.User{},
 		&models.Achievement{},
 		&models.UserAchievement{},
+		&models.UserCounter{},
+		&models.UserCounterItem{},
 		&models.Garden{},
 		&models.PlantType{},
 		&models.Plant{},
+		&models.InventoryItem{},
 		&models.Weather{},
 		&models.WeatherForecast{},
-	)
+		&models.LedgerEntry{},
+		&models.HarvestRecord{},
+	); err != nil {
+		return err
+	}
+
+	if err := d.openLedgers(); err != nil {
+		return err
+	}
+	if err := d.runOnce("explain_past_harvest_deaths", explainPastHarvests); err != nil {
+		return err
+	}
+	if err := d.runOnce("record_past_harvests", recordPastHarvests); err != nil {
+		return err
+	}
+	return d.runOnce("count_past_achievement_progress", countPastProgress)
+}
+
+// explainPastHarvests records why plants that withered from being harvested before
+// death reasons were kept died, and when
+func explainPastHarvests(tx *gorm.DB) error {
+	result := tx.Model(&models.Plant{}).
+		Where("stage = ? AND harvested_at IS NOT NULL AND (death_reason IS NULL OR death_reason = '')", models.PlantStageWithered).
+		Updates(map[string]interface{}{
+			"death_reason": models.DeathHarvested,
+			"withered_at":  gorm.Expr("COALESCE(withered_at, harvested_at)"),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to record past harvest deaths: %w", result.Error)
+	}
+	return nil
+}
+
+// recordPastHarvests creates harvest records for plants harvested before they were kept.
+// The weather at the time wasn't recorded, so it is left blank.
+func recordPastHarvests(tx *gorm.DB) error {
+	result := tx.Exec(`INSERT INTO harvest_records (id, user_id, garden_id, plant_id, plant_type_id, position,
+			planted_at, harvested_at, growth_duration_seconds, health, water_level, fertilizer_level, season,
+			items_harvested, item_value, experience_earned, created_at)
+		SELECT gen_random_uuid(), gardens.user_id, plants.garden_id, plants.id, plants.plant_type_id, plants.position,
+			plants.planted_at, plants.harvested_at, EXTRACT(EPOCH FROM plants.harvested_at - plants.planted_at)::bigint,
+			plants.health, plants.water_level, plants.fertilizer_level,
+			CASE
+				WHEN EXTRACT(MONTH FROM plants.harvested_at) BETWEEN 3 AND 5 THEN 'spring'
+				WHEN EXTRACT(MONTH FROM plants.harvested_at) BETWEEN 6 AND 8 THEN 'summer'
+				WHEN EXTRACT(MONTH FROM plants.harvested_at) BETWEEN 9 AND 11 THEN 'autumn'
+				ELSE 'winter'
+			END,
+			plant_types.yield, plant_types.harvest_value * plant_types.yield, plant_types.experience_value, NOW()
+		FROM plants
+		JOIN gardens ON plants.garden_id = gardens.id
+		JOIN plant_types ON plants.plant_type_id = plant_types.id
+		WHERE plants.harvested_at IS NOT NULL
+		ON CONFLICT (plant_id, harvest_number) DO NOTHING`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to record past harvests: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Recorded %d past harvests", result.RowsAffected)
+	}
+	return nil
+}
+
+// plantsPlanted lists the plants each user has planted that are still around, either
+// in a garden or in a harvest record
+const plantsPlanted = `SELECT gardens.user_id, plants.id AS plant_id, plants.plant_type_id
+	FROM plants JOIN gardens ON plants.garden_id = gardens.id
+	UNION
+	SELECT user_id, plant_id, plant_type_id FROM harvest_records`
+
+// countPastProgress counts achievement progress made before it was tracked, from what
+// is left of it in gardens, plants, harvest records and levels. Counters only ever go
+// up, so progress counted as it happened is kept. Weather checks left no trace.
+func countPastProgress(tx *gorm.DB) error {
+	// Plant types already counted aren't counted again when they are next planted
+	if err := tx.Exec(`INSERT INTO user_counter_items (user_id, counter, item, created_at)
+		SELECT DISTINCT user_id, 'plant_types_planted', plant_type_id::text, NOW() FROM (` + plantsPlanted + `) planted
+		ON CONFLICT DO NOTHING`).Error; err != nil {
+		return fmt.Errorf("failed to count past plant types planted: %w", err)
+	}
+
+	counters := []struct{ counter, query string }{
+		{"gardens_created", `SELECT user_id, 'gardens_created', COUNT(*) FROM gardens GROUP BY user_id`},
+		{"plants_planted", `SELECT user_id, 'plants_planted', COUNT(*) FROM (` + plantsPlanted + `) planted GROUP BY user_id`},
+		{"plant_types_planted", `SELECT user_id, counter, COUNT(*) FROM user_counter_items WHERE counter = 'plant_types_planted' GROUP BY user_id, counter`},
+		{"plants_harvested", `SELECT user_id, 'plants_harvested', COUNT(*) FROM harvest_records GROUP BY user_id`},
+		{"plant type harvest", `SELECT user_id, 'plants_harvested:' || plant_type_id::text, COUNT(*) FROM harvest_records GROUP BY user_id, plant_type_id`},
+		{"level", `SELECT id, 'level', level FROM users WHERE level > 0`},
+	}
+	for _, c := range counters {
+		result := tx.Exec(`INSERT INTO user_counters (user_id, counter, value, updated_at)
+			SELECT counted.*, NOW() FROM (` + c.query + `) counted
+			ON CONFLICT (user_id, counter) DO UPDATE SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at
+			WHERE user_counters.value < EXCLUDED.value`)
+		if result.Error != nil {
+			return fmt.Errorf("failed to count past %s progress: %w", c.counter, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Counted past %s progress for %d users", c.counter, result.RowsAffected)
+		}
+	}
+	return nil
+}
+
+// openLedgers gives users who predate the ledger an opening balance entry, so their
+// balances reconcile with it
+func (d *Database) openLedgers() error {
+	for _, currency := range []models.LedgerCurrency{models.LedgerCoins, models.LedgerExperience} {
+		column := string(currency)
+		result := d.DB.Exec(`INSERT INTO ledger_entries (id, user_id, currency, amount, balance_after, reason, created_at)
+			SELECT gen_random_uuid(), users.id, ?, users.`+column+`, users.`+column+`, ?, NOW()
+			FROM users
+			WHERE users.`+column+` <> 0
+			AND NOT EXISTS (SELECT 1 FROM ledger_entries WHERE ledger_entries.user_id = users.id AND ledger_entries.currency = ?)`,
+			currency, models.LedgerOpeningBalance, currency)
+		if result.Error != nil {
+			return fmt.Errorf("failed to open %s ledgers: %w", currency, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Opened %s ledgers for %d users", currency, result.RowsAffected)
+		}
+	}
+	return nil
 }
 
 func (d *Database) Seed() error {
@@ -77,13 +203,17 @@ func (d *Database) Seed() error {
 			Icon:        "🌱",
 			Points:      10,
 			Category:    "gardening",
+			Counter:     "gardens_created",
+			Goal:        1,
 		},
 		{
 			Name:        "Plant Master",
-			Description: "Plant 10 different types of plants",
+			Description: "Plant 5 different types of plants",
 			Icon:        "🌿",
 			Points:      25,
 			Category:    "gardening",
+			Counter:     "plant_types_planted",
+			Goal:        5,
 		},
 		{
 			Name:        "Harvest King",
@@ -91,6 +221,8 @@ func (d *Database) Seed() error {
 			Icon:        "👑",
 			Points:      50,
 			Category:    "harvesting",
+			Counter:     "plants_harvested",
+			Goal:        50,
 		},
 		{
 			Name:        "Weather Watcher",
@@ -98,6 +230,8 @@ func (d *Database) Seed() error {
 			Icon:        "🌤️",
 			Points:      15,
 			Category:    "weather",
+			Counter:     "weather_checks",
+			Goal:        10,
 		},
 		{
 			Name:        "Level 10",
@@ -105,6 +239,8 @@ func (d *Database) Seed() error {
 			Icon:        "⭐",
 			Points:      100,
 			Category:    "progression",
+			Counter:     "level",
+			Goal:        10,
 		},
 	}
 
@@ -118,7 +254,27 @@ func (d *Database) Seed() error {
 			} else {
 				return fmt.Errorf("failed to check achievement %s: %w", achievement.Name, err)
 			}
+			continue
 		}
+
+		// Achievements seeded before they had rules never unlock, so give them theirs
+		if existing.Counter == "" {
+			if err := d.DB.Model(&existing).Updates(map[string]interface{}{
+				"counter": achievement.Counter,
+				"goal":    achievement.Goal,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update achievement %s: %w", achievement.Name, err)
+			}
+		}
+	}
+
+	// Plant Master used to ask for more plant types than the game has
+	if err := d.runOnce("lower_plant_master_goal", func(tx *gorm.DB) error {
+		return tx.Model(&models.Achievement{}).
+			Where("name = ? AND goal = ?", "Plant Master", 10).
+			Updates(map[string]interface{}{"description": "Plant 5 different types of plants", "goal": 5}).Error
+	}); err != nil {
+		return err
 	}
 
 	// Seed plant types
@@ -133,10 +289,16 @@ func (d *Database) Seed() error {
 			Yield:           3,
 			HarvestValue:    15,
 			ExperienceValue: 10,
+			OptimalTempMin:  20,
+			OptimalTempMax:  30,
+			FrostThreshold:  8,
+			HeatThreshold:   35,
+			SeedPrice:       5,
 			MinLevel:        1,
 			Season:          "summer",
 			Weather:         "sunny",
 			Rarity:          "common",
+			GrowthModel:     "vine",
 		},
 		{
 			Name:            "Carrot",
@@ -148,10 +310,16 @@ func (d *Database) Seed() error {
 			Yield:           2,
 			HarvestValue:    12,
 			ExperienceValue: 8,
+			OptimalTempMin:  12,
+			OptimalTempMax:  22,
+			FrostThreshold:  -4,
+			HeatThreshold:   30,
+			SeedPrice:       4,
 			MinLevel:        1,
 			Season:          "spring",
 			Weather:         "all",
 			Rarity:          "common",
+			GrowthModel:     "default",
 		},
 		{
 			Name:            "Lettuce",
@@ -163,10 +331,16 @@ func (d *Database) Seed() error {
 			Yield:           1,
 			HarvestValue:    8,
 			ExperienceValue: 5,
+			OptimalTempMin:  10,
+			OptimalTempMax:  20,
+			FrostThreshold:  -1,
+			HeatThreshold:   27,
+			SeedPrice:       3,
 			MinLevel:        1,
 			Season:          "spring",
 			Weather:         "cloudy",
 			Rarity:          "common",
+			GrowthModel:     "herb",
 		},
 		{
 			Name:            "Strawberry",
@@ -178,10 +352,19 @@ func (d *Database) Seed() error {
 			Yield:           2,
 			HarvestValue:    25,
 			ExperienceValue: 15,
+			OptimalTempMin:  15,
+			OptimalTempMax:  26,
+			FrostThreshold:  1,
+			HeatThreshold:   30,
+			SeedPrice:       12,
 			MinLevel:        3,
 			Season:          "spring",
 			Weather:         "sunny",
 			Rarity:          "uncommon",
+			GrowthModel:     "vine",
+			MaxHarvests:     3,
+			RegrowStage:     models.PlantStageMature,
+			RegrowProgress:  80,
 		},
 		{
 			Name:            "Golden Apple",
@@ -193,10 +376,20 @@ func (d *Database) Seed() error {
 			Yield:           1,
 			HarvestValue:    100,
 			ExperienceValue: 50,
+			OptimalTempMin:  10,
+			OptimalTempMax:  24,
+			FrostThreshold:  -8,
+			HeatThreshold:   34,
+			SeedPrice:       60,
 			MinLevel:        10,
 			Season:          "autumn",
 			Weather:         "sunny",
+			SeasonPolicy:    "reject",
 			Rarity:          "legendary",
+			GrowthModel:     "tree",
+			MaxHarvests:     5,
+			RegrowStage:     models.PlantStageMature,
+			RegrowProgress:  65,
 		},
 	}
 
@@ -213,6 +406,10 @@ func (d *Database) Seed() error {
 		}
 	}
 
+	if err := d.backfillPlantTypes(plantTypes); err != nil {
+		return err
+	}
+
 	log.Println("Database seeding completed successfully")
 	return nil
 }
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/my-garden/api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Migration records a one-time data migration that has been applied
+type Migration struct {
+	Name      string    `gorm:"primaryKey"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (Migration) TableName() string {
+	return "schema_migrations"
+}
+
+// runOnce applies a one-time data migration in a transaction with its record, so it
+// never runs again. Replicas starting together wait on each other's record, so only
+// one of them applies it.
+func (d *Database) runOnce(name string, migrate func(tx *gorm.DB) error) error {
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&Migration{Name: name, AppliedAt: time.Now()})
+		if result.Error != nil {
+			return fmt.Errorf("failed to record migration %s: %w", name, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		log.Printf("Applying migration %s", name)
+		if err := migrate(tx); err != nil {
+			return fmt.Errorf("migration %s failed: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// plantTypeBackfill gives plant types seeded before a column existed their seeded
+// value for it, returning the columns to update
+type plantTypeBackfill struct {
+	name    string
+	columns func(seeded, existing models.PlantType) map[string]interface{}
+}
+
+// plantTypeBackfills each run once, in order. Plant types edited since keep their edits.
+var plantTypeBackfills = []plantTypeBackfill{
+	{
+		name: "backfill_plant_type_season_policies",
+		columns: func(seeded, existing models.PlantType) map[string]interface{} {
+			if seeded.SeasonPolicy == "" || seeded.SeasonPolicy == existing.SeasonPolicy {
+				return nil
+			}
+			return map[string]interface{}{"season_policy": seeded.SeasonPolicy}
+		},
+	},
+	{
+		name: "backfill_plant_type_growth_models",
+		columns: func(seeded, existing models.PlantType) map[string]interface{} {
+			if seeded.GrowthModel == "" || seeded.GrowthModel == existing.GrowthModel {
+				return nil
+			}
+			return map[string]interface{}{"growth_model": seeded.GrowthModel}
+		},
+	},
+	{
+		// Plant types seeded before seed prices were tiered all cost the column default
+		name: "backfill_plant_type_seed_prices",
+		columns: func(seeded, existing models.PlantType) map[string]interface{} {
+			if seeded.SeedPrice == existing.SeedPrice {
+				return nil
+			}
+			return map[string]interface{}{"seed_price": seeded.SeedPrice}
+		},
+	},
+	{
+		// Plant types seeded before perennials existed are all harvested once
+		name: "backfill_plant_type_perennials",
+		columns: func(seeded, existing models.PlantType) map[string]interface{} {
+			if !seeded.Perennial() || existing.Perennial() {
+				return nil
+			}
+			return map[string]interface{}{
+				"max_harvests":    seeded.MaxHarvests,
+				"regrow_stage":    seeded.RegrowStage,
+				"regrow_progress": seeded.RegrowProgress,
+			}
+		},
+	},
+	{
+		// Plant types seeded before temperatures mattered all have the column defaults
+		name: "backfill_plant_type_temperatures",
+		columns: func(seeded, existing models.PlantType) map[string]interface{} {
+			return map[string]interface{}{
+				"optimal_temp_min": seeded.OptimalTempMin,
+				"optimal_temp_max": seeded.OptimalTempMax,
+				"frost_threshold":  seeded.FrostThreshold,
+				"heat_threshold":   seeded.HeatThreshold,
+			}
+		},
+	},
+}
+
+// backfillPlantTypes runs the plant type backfills that haven't run yet against the
+// seeded plant types
+func (d *Database) backfillPlantTypes(seeded []models.PlantType) error {
+	for _, backfill := range plantTypeBackfills {
+		err := d.runOnce(backfill.name, func(tx *gorm.DB) error {
+			for _, plantType := range seeded {
+				var existing models.PlantType
+				if err := tx.Where("name = ?", plantType.Name).First(&existing).Error; err != nil {
+					if err == gorm.ErrRecordNotFound {
+						continue
+					}
+					return fmt.Errorf("failed to check plant type %s: %w", plantType.Name, err)
+				}
+
+				updates := backfill.columns(plantType, existing)
+				if len(updates) == 0 {
+					continue
+				}
+				if err := tx.Model(&existing).Updates(updates).Error; err != nil {
+					return fmt.Errorf("failed to update plant type %s: %w", plantType.Name, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,9 +5,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/my-garden/api/internal/database"
 	"github.com/my-garden/api/internal/models"
 	"github.com/my-garden/api/pkg/auth"
+	"github.com/my-garden/api/pkg/game"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -15,12 +17,14 @@ import (
 type AuthHandler struct {
 	db         *database.Database
 	jwtManager *auth.JWTManager
+	gameEngine *game.GameEngine
 }
 
-func NewAuthHandler(db *database.Database, jwtManager *auth.JWTManager) *AuthHandler {
+func NewAuthHandler(db *database.Database, jwtManager *auth.JWTManager, gameEngine *game.GameEngine) *AuthHandler {
 	return &AuthHandler{
 		db:         db,
 		jwtManager: jwtManager,
+		gameEngine: gameEngine,
 	}
 }
 
@@ -91,7 +95,14 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		LastName:     req.LastName,
 	}
 
-	if err := h.db.DB.Create(&user).Error; err != nil {
+	// Create the user and record their starting balances together
+	err = h.db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return game.OpenLedger(tx, &user)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
@@ -106,7 +117,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	// Update last login
 	now := time.Now()
 	user.LastLoginAt = &now
-	h.db.DB.Save(&user)
+	h.db.DB.Model(&user).UpdateColumn("last_login_at", now)
 
 	// Clear password hash from response
 	user.PasswordHash = ""
@@ -164,7 +175,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// Update last login
 	now := time.Now()
 	user.LastLoginAt = &now
-	h.db.DB.Save(&user)
+	h.db.DB.Model(&user).UpdateColumn("last_login_at", now)
 
 	// Clear password hash from response
 	user.PasswordHash = ""
@@ -231,12 +242,12 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 // GetProfile godoc
 // @Summary Get user profile
-// @Description Get the current user's profile and achievements
+// @Description Get the current user's profile, unlocked achievements and progress towards every achievement
 // @Tags users
 // @Accept json
 // @Produce json
 // @Security bearer
-// @Success 200 {object} map[string]interface{} "User profile with achievements"
+// @Success 200 {object} map[string]interface{} "User profile with achievements and achievement progress"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 404 {object} map[string]interface{} "User not found"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -261,7 +272,16 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	// Clear password hash from response
 	user.PasswordHash = ""
 
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	progress, err := h.gameEngine.Achievements().Progress(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch achievement progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":                 user,
+		"achievement_progress": progress,
+	})
 }
 
 // UpdateProfile godoc
@@ -321,7 +341,10 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		user.Language = req.Language
 	}
 
-	if err := h.db.DB.Save(&user).Error; err != nil {
+	// Only write profile fields, so balances changed meanwhile aren't overwritten
+	if err := h.db.DB.Model(&user).
+		Select("first_name", "last_name", "avatar", "timezone", "language").
+		Updates(&user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
 		return
 	}
@@ -331,3 +354,62 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"user": user})
 }
+
+type LedgerQuery struct {
+	Currency string `form:"currency" binding:"omitempty,oneof=coins experience" example:"coins"`
+	Page     int    `form:"page" binding:"omitempty,min=1" example:"1"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=100" example:"50"`
+}
+
+// GetLedger godoc
+// @Summary Get coin and experience ledger
+// @Description Get every change to the current user's coins and experience, newest first, with why it happened and the balance after it. Also checks that the user's balances equal the sum of their ledger.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security bearer
+// @Param currency query string false "coins or experience"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Entries per page (max 100)" default(50)
+// @Success 200 {object} map[string]interface{} "Ledger page and reconciliation"
+// @Failure 400 {object} map[string]interface{} "Bad Request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/ledger [get]
+func (h *AuthHandler) GetLedger(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var query LedgerQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 50
+	}
+
+	ledger := h.gameEngine.Ledger()
+	page, err := ledger.Entries(userID.(uuid.UUID), models.LedgerCurrency(query.Currency), query.Page, query.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ledger"})
+		return
+	}
+
+	reconciliation, err := ledger.Reconcile(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile ledger"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ledger":         page,
+		"reconciliation": reconciliation,
+	})
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/my-garden/api/internal/database"
+	"github.com/my-garden/api/internal/models"
+	"github.com/my-garden/api/pkg/game"
+)
+
+type GameHandler struct {
+	db         *database.Database
+	gameEngine *game.GameEngine
+}
+
+func NewGameHandler(db *database.Database, gameEngine *game.GameEngine) *GameHandler {
+	return &GameHandler{
+		db:         db,
+		gameEngine: gameEngine,
+	}
+}
+
+// GetStatus godoc
+// @Summary Get game status
+// @Description Get the game clock, season, current weather and when it changes, the tick schedule and the health of the simulation engine. Authenticated callers also get their own stats.
+// @Tags game
+// @Accept json
+// @Produce json
+// @Security bearer
+// @Success 200 {object} map[string]interface{} "Game status"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /game/status [get]
+func (h *GameHandler) GetStatus(c *gin.Context) {
+	status := h.gameEngine.Status(c.Request.Context())
+	response := gin.H{"status": status}
+
+	if userID, exists := c.Get("user_id"); exists {
+		var user models.User
+		if err := h.db.DB.First(&user, userID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return
+		}
+
+		var gardensCount, plantsCount int64
+		if err := h.db.DB.Model(&models.Garden{}).Where("user_id = ?", userID).Count(&gardensCount).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count gardens"})
+			return
+		}
+		if err := h.db.DB.Model(&models.Plant{}).
+			Joins("JOIN gardens ON plants.garden_id = gardens.id").
+			Where("gardens.user_id = ?", userID).
+			Count(&plantsCount).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count plants"})
+			return
+		}
+
+		response["user_stats"] = gin.H{
+			"level":         user.Level,
+			"experience":    user.Experience,
+			"coins":         user.Coins,
+			"gardens_count": gardensCount,
+			"plants_count":  plantsCount,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type LeaderboardQuery struct {
+	Board       string `form:"board" binding:"omitempty,oneof=experience coins harvests" example:"experience"`
+	Window      string `form:"window" binding:"omitempty,oneof=all_time weekly seasonal" example:"weekly"`
+	PlantTypeID string `form:"plant_type_id" binding:"omitempty,uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Page        int    `form:"page" binding:"omitempty,min=1" example:"1"`
+	Limit       int    `form:"limit" binding:"omitempty,min=1,max=100" example:"20"`
+}
+
+// board resolves the leaderboard and window a query asks for, applying defaults
+func (q LeaderboardQuery) board() (game.LeaderboardBoard, game.LeaderboardWindow) {
+	board := game.BoardExperience
+	if q.Board != "" {
+		board = game.LeaderboardBoard(q.Board)
+	}
+	if q.PlantTypeID != "" {
+		board = game.PlantTypeBoard(uuid.MustParse(q.PlantTypeID))
+	}
+
+	window := game.WindowAllTime
+	if q.Window != "" {
+		window = game.LeaderboardWindow(q.Window)
+	}
+
+	return board, window
+}
+
+// GetLeaderboard godoc
+// @Summary Get leaderboard
+// @Description Get a page of player rankings by experience, coins earned or harvests, for all time, this week or this season. Pass plant_type_id to rank harvests of a single plant type.
+// @Tags game
+// @Accept json
+// @Produce json
+// @Param board query string false "experience, coins or harvests" default(experience)
+// @Param window query string false "all_time, weekly or seasonal" default(all_time)
+// @Param plant_type_id query string false "Rank harvests of this plant type"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Entries per page (max 100)" default(20)
+// @Success 200 {object} map[string]interface{} "Leaderboard page"
+// @Failure 400 {object} map[string]interface{} "Bad Request"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /game/leaderboard [get]
+func (h *GameHandler) GetLeaderboard(c *gin.Context) {
+	var query LeaderboardQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if query.PlantTypeID != "" && query.Board != "" && query.Board != string(game.BoardHarvests) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "plant_type_id can only be used with the harvests board"})
+		return
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	board, window := query.board()
+	page, err := h.gameEngine.Leaderboard().Top(c.Request.Context(), board, window, query.Page, query.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leaderboard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"leaderboard": page})
+}
+
+// GetMyRank godoc
+// @Summary Get my leaderboard rank
+// @Description Get the current user's rank and score on a leaderboard
+// @Tags game
+// @Accept json
+// @Produce json
+// @Security bearer
+// @Param board query string false "experience, coins or harvests" default(experience)
+// @Param window query string false "all_time, weekly or seasonal" default(all_time)
+// @Param plant_type_id query string false "Rank harvests of this plant type"
+// @Success 200 {object} map[string]interface{} "User's rank"
+// @Failure 400 {object} map[string]interface{} "Bad Request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /game/leaderboard/me [get]
+func (h *GameHandler) GetMyRank(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var query LeaderboardQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if query.PlantTypeID != "" && query.Board != "" && query.Board != string(game.BoardHarvests) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "plant_type_id can only be used with the harvests board"})
+		return
+	}
+
+	board, window := query.board()
+	rank, err := h.gameEngine.Leaderboard().RankOf(c.Request.Context(), board, window, userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rank"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rank": rank})
+}
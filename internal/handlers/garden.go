@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"fmt"
+	"math"
 	"net/http"
 	"time"
 
@@ -8,15 +10,20 @@ import (
 	"github.com/google/uuid"
 	"github.com/my-garden/api/internal/database"
 	"github.com/my-garden/api/internal/models"
+	"github.com/my-garden/api/pkg/game"
 	"gorm.io/gorm"
 )
 
 type GardenHandler struct {
-	db *database.Database
+	db         *database.Database
+	gameEngine *game.GameEngine
 }
 
-func NewGardenHandler(db *database.Database) *GardenHandler {
-	return &GardenHandler{db: db}
+func NewGardenHandler(db *database.Database, gameEngine *game.GameEngine) *GardenHandler {
+	return &GardenHandler{
+		db:         db,
+		gameEngine: gameEngine,
+	}
 }
 
 type CreateGardenRequest struct {
@@ -29,9 +36,16 @@ type UpdateGardenRequest struct {
 	Description string `json:"description" example:"Updated garden description"`
 }
 
+// Error codes returned alongside the message so clients can tell failures apart
+const (
+	ErrCodeInsufficientCoins = "insufficient_coins"
+	ErrCodeLevelTooLow       = "level_too_low"
+	ErrCodeNotEnoughItems    = "not_enough_items"
+)
+
 type PlantRequest struct {
 	PlantTypeID uuid.UUID `json:"plant_type_id" binding:"required" example:"123e4567-e89b-12d3-a456-426614174000"`
-	Position    *int      `json:"position" binding:"required,min=0,max=8" example:"0"`
+	Position    *int      `json:"position" binding:"required,min=0" example:"0"`
 }
 
 type WaterPlantRequest struct {
@@ -42,6 +56,11 @@ type FertilizePlantRequest struct {
 	Amount int `json:"amount" binding:"required,min=1,max=100" example:"20"`
 }
 
+type RevivePlantRequest struct {
+	PayWith string                `json:"pay_with" binding:"required,oneof=coins items" example:"items"`
+	Quality models.HarvestQuality `json:"quality" binding:"omitempty,oneof=poor normal good excellent" example:"normal"` // quality of the goods paid with, defaults to normal
+}
+
 // GetGardens godoc
 // @Summary Get user gardens
 // @Description Get all gardens for the current user
@@ -66,6 +85,15 @@ func (h *GardenHandler) GetGardens(c *gin.Context) {
 		return
 	}
 
+	// Catch plants up with the time that passed since they were last evaluated
+	for i := range gardens {
+		if err := h.gameEngine.MaterializeGarden(&gardens[i]); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update plants"})
+			return
+		}
+		game.DescribeGrid(&gardens[i])
+	}
+
 	c.JSON(http.StatusOK, gin.H{"gardens": gardens})
 }
 
@@ -99,12 +127,18 @@ func (h *GardenHandler) CreateGarden(c *gin.Context) {
 		UserID:      userID.(uuid.UUID),
 		Name:        req.Name,
 		Description: req.Description,
+		Width:       game.StarterGardenWidth,
+		Height:      game.StarterGardenHeight,
+		Size:        game.StarterGardenWidth * game.StarterGardenHeight,
 	}
 
 	if err := h.db.DB.Create(&garden).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create garden"})
 		return
 	}
+	game.DescribeGrid(&garden)
+
+	h.gameEngine.PublishEvent(game.NewGardenCreatedEvent(&garden))
 
 	c.JSON(http.StatusCreated, gin.H{"garden": garden})
 }
@@ -146,6 +180,13 @@ func (h *GardenHandler) GetGarden(c *gin.Context) {
 		return
 	}
 
+	// Catch plants up with the time that passed since they were last evaluated
+	if err := h.gameEngine.MaterializeGarden(&garden); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update plants"})
+		return
+	}
+	game.DescribeGrid(&garden)
+
 	c.JSON(http.StatusOK, gin.H{"garden": garden})
 }
 
@@ -258,7 +299,7 @@ func (h *GardenHandler) DeleteGarden(c *gin.Context) {
 
 // PlantSeed godoc
 // @Summary Plant a seed
-// @Description Plant a seed in a garden
+// @Description Plant a seed in a garden, paying the plant type's seed price. The player must have reached the plant type's minimum level. Out-of-season plant types are rejected or planted with a warning, depending on their season policy.
 // @Tags plants
 // @Accept json
 // @Produce json
@@ -266,8 +307,10 @@ func (h *GardenHandler) DeleteGarden(c *gin.Context) {
 // @Param id path string true "Garden ID" example("123e4567-e89b-12d3-a456-426614174000")
 // @Param request body PlantRequest true "Plant data"
 // @Success 201 {object} map[string]interface{} "Planted seed"
-// @Failure 400 {object} map[string]interface{} "Bad Request"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid data, position outside the garden or plant type out of season"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 402 {object} map[string]interface{} "Insufficient coins (code insufficient_coins)"
+// @Failure 403 {object} map[string]interface{} "Level too low (code level_too_low)"
 // @Failure 404 {object} map[string]interface{} "Garden or plant type not found"
 // @Failure 409 {object} map[string]interface{} "Position already occupied"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
@@ -302,6 +345,11 @@ func (h *GardenHandler) PlantSeed(c *gin.Context) {
 		return
 	}
 
+	if !garden.Contains(*req.Position) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Position must be between 0 and %d", garden.Width*garden.Height-1)})
+		return
+	}
+
 	// Check if position is already occupied
 	var existingPlant models.Plant
 	if err := h.db.DB.Where("garden_id = ? AND position = ?", gardenID, req.Position).First(&existingPlant).Error; err == nil {
@@ -320,15 +368,88 @@ func (h *GardenHandler) PlantSeed(c *gin.Context) {
 		return
 	}
 
+	// Check the player can grow and afford this plant type
+	var user models.User
+	if err := h.db.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	if user.Level < plantType.MinLevel {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":          fmt.Sprintf("%s requires level %d", plantType.Name, plantType.MinLevel),
+			"code":           ErrCodeLevelTooLow,
+			"required_level": plantType.MinLevel,
+			"level":          user.Level,
+		})
+		return
+	}
+	if user.Coins < plantType.SeedPrice {
+		c.JSON(http.StatusPaymentRequired, insufficientCoins(plantType, user.Coins))
+		return
+	}
+
+	// Check the plant type's season preference
+	now := time.Now()
+	var warnings []string
+	if season := models.GetSeason(now); !plantType.InSeason(season) {
+		switch plantType.SeasonPolicy {
+		case models.SeasonPolicyReject:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s can only be planted in %s", plantType.Name, plantType.Season)})
+			return
+		case models.SeasonPolicyAllow:
+			// Out-of-season growth penalties still apply
+		default:
+			warnings = append(warnings, fmt.Sprintf("%s prefers %s and will grow slowly in %s", plantType.Name, plantType.Season, season))
+		}
+	}
+
 	// Create plant
 	plant := models.Plant{
-		GardenID:    gardenID,
-		PlantTypeID: req.PlantTypeID,
-		Position:    *req.Position,
-		PlantedAt:   time.Now(),
+		ID:              uuid.New(),
+		GardenID:        gardenID,
+		PlantTypeID:     req.PlantTypeID,
+		Position:        *req.Position,
+		PlantedAt:       now,
+		LastEvaluatedAt: now,
+	}
+
+	// Pay for the seed and plant it in one transaction
+	tx := h.db.DB.Begin()
+	if _, err := game.ApplyBalanceChange(tx, game.BalanceChange{
+		UserID:        user.ID,
+		Coins:         -plantType.SeedPrice,
+		Reason:        models.LedgerSeedPurchase,
+		ReferenceType: "plant",
+		ReferenceID:   plant.ID,
+		Note:          plantType.Name,
+	}); err != nil {
+		tx.Rollback()
+		if err == game.ErrInsufficientCoins {
+			// Coins were spent elsewhere since we checked
+			c.JSON(http.StatusPaymentRequired, insufficientCoins(plantType, user.Coins))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
 	}
 
-	if err := h.db.DB.Create(&plant).Error; err != nil {
+	// Seedlings get a head start from the garden's compost
+	if compost := int(math.Min(float64(garden.FertilizerLevel), float64(plantType.FertilizerNeeds))); compost > 0 {
+		if err := tx.Model(&garden).UpdateColumn("fertilizer_level", gorm.Expr("fertilizer_level - ?", compost)).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update garden"})
+			return
+		}
+		plant.FertilizerLevel = compost
+	}
+
+	if err := tx.Create(&plant).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to plant seed"})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to plant seed"})
 		return
 	}
@@ -336,7 +457,14 @@ func (h *GardenHandler) PlantSeed(c *gin.Context) {
 	// Load plant type for response
 	h.db.DB.Preload("PlantType").First(&plant, plant.ID)
 
-	c.JSON(http.StatusCreated, gin.H{"plant": plant})
+	h.gameEngine.RefreshCondition(&plant)
+	h.gameEngine.PublishEvent(game.NewPlantUpdatedEvent(garden.UserID, game.UpdatePlantPlanted, &plant))
+
+	response := gin.H{"plant": plant, "coins_spent": plantType.SeedPrice}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	c.JSON(http.StatusCreated, response)
 }
 
 // WaterPlant godoc
@@ -383,6 +511,7 @@ func (h *GardenHandler) WaterPlant(c *gin.Context) {
 	// Check if plant exists and belongs to user's garden
 	var plant models.Plant
 	if err := h.db.DB.Joins("JOIN gardens ON plants.garden_id = gardens.id").
+		Preload("PlantType").
 		Where("plants.id = ? AND gardens.id = ? AND gardens.user_id = ?", plantID, gardenID, userID).
 		First(&plant).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -393,9 +522,16 @@ func (h *GardenHandler) WaterPlant(c *gin.Context) {
 		return
 	}
 
+	// Apply elapsed growth before changing the plant
+	if err := h.gameEngine.MaterializePlant(&plant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update plant"})
+		return
+	}
+
 	// Update water level
 	now := time.Now()
-	plant.WaterLevel = min(100, plant.WaterLevel+req.Amount)
+	health, water, fertilizer := plant.Levels()
+	plant.SetLevels(health, math.Min(100, water+float64(req.Amount)), fertilizer)
 	plant.LastWateredAt = &now
 
 	if err := h.db.DB.Save(&plant).Error; err != nil {
@@ -403,6 +539,9 @@ func (h *GardenHandler) WaterPlant(c *gin.Context) {
 		return
 	}
 
+	h.gameEngine.RefreshCondition(&plant)
+	h.gameEngine.PublishEvent(game.NewPlantUpdatedEvent(userID.(uuid.UUID), game.UpdatePlantWatered, &plant))
+
 	c.JSON(http.StatusOK, gin.H{"plant": plant})
 }
 
@@ -450,6 +589,7 @@ func (h *GardenHandler) FertilizePlant(c *gin.Context) {
 	// Check if plant exists and belongs to user's garden
 	var plant models.Plant
 	if err := h.db.DB.Joins("JOIN gardens ON plants.garden_id = gardens.id").
+		Preload("PlantType").
 		Where("plants.id = ? AND gardens.id = ? AND gardens.user_id = ?", plantID, gardenID, userID).
 		First(&plant).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -460,8 +600,16 @@ func (h *GardenHandler) FertilizePlant(c *gin.Context) {
 		return
 	}
 
+	// Apply elapsed growth before changing the plant
+	if err := h.gameEngine.MaterializePlant(&plant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update plant"})
+		return
+	}
+
 	// Update fertilizer level
 	now := time.Now()
+	health, water, fertilizer := plant.Levels()
+	plant.SetLevels(health, water, math.Min(100, fertilizer+float64(req.Amount)))
 	plant.LastFertilizedAt = &now
 
 	if err := h.db.DB.Save(&plant).Error; err != nil {
@@ -469,12 +617,15 @@ func (h *GardenHandler) FertilizePlant(c *gin.Context) {
 		return
 	}
 
+	h.gameEngine.RefreshCondition(&plant)
+	h.gameEngine.PublishEvent(game.NewPlantUpdatedEvent(userID.(uuid.UUID), game.UpdatePlantFertilized, &plant))
+
 	c.JSON(http.StatusOK, gin.H{"plant": plant})
 }
 
 // HarvestPlant godoc
 // @Summary Harvest a plant
-// @Description Harvest a mature plant to get rewards
+// @Description Harvest a mature plant, adding its yield to the inventory and earning experience. The harvest is graded poor, normal, good or excellent by how well the plant was cared for, which scales its yield, value and experience. Perennials grow back after all but their last harvest.
 // @Tags plants
 // @Accept json
 // @Produce json
@@ -520,6 +671,12 @@ func (h *GardenHandler) HarvestPlant(c *gin.Context) {
 		return
 	}
 
+	// Apply elapsed growth so a plant that ripened since the last read can be harvested
+	if err := h.gameEngine.MaterializePlant(&plant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update plant"})
+		return
+	}
+
 	// Check if plant is harvestable
 	if plant.Stage != models.PlantStageHarvestable {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Plant is not ready for harvest"})
@@ -533,26 +690,39 @@ func (h *GardenHandler) HarvestPlant(c *gin.Context) {
 		return
 	}
 
-	// Calculate harvest rewards
-	coinsEarned := plant.PlantType.HarvestValue * plant.PlantType.Yield
-	experienceEarned := plant.PlantType.ExperienceValue
-
-	// Update user stats
-	user.Coins += coinsEarned
-	user.Experience += experienceEarned
-
-	// Check for level up
-	oldLevel := user.Level
-	user.Level = calculateLevel(user.Experience)
-
-	// Update plant status
+	// Grade the harvest by how well the plant was looked after; the grade scales every reward.
+	// Plants left ripe for too long lose part of their yield. The yield goes into the
+	// user's inventory to be sold or used later.
 	now := time.Now()
-	plant.HarvestedAt = &now
-	plant.Stage = models.PlantStageWithered
+	weather, _ := h.gameEngine.GetCurrentWeather()
+	grade := game.GradeHarvest(&plant, weather)
+	freshness := game.Freshness(&plant, now)
+	itemsHarvested := game.FreshYield(game.HarvestYield(&plant.PlantType, grade.Quality), freshness)
+	experienceEarned := game.HarvestExperience(&plant.PlantType, grade.Quality)
+
+	// Keep a record of the harvest once the plant is gone
+	record := game.NewHarvestRecord(user.ID, &plant, weather, now)
+	record.ItemsHarvested = itemsHarvested
+	record.ItemValue = game.SalePrice(&plant.PlantType, grade.Quality, itemsHarvested)
+	record.ExperienceEarned = experienceEarned
+	record.Quality = grade.Quality
+	record.QualityScore = grade.Score
+	record.Freshness = freshness
+
+	// Perennials start growing back, everything else withers
+	regrowing := game.FinishHarvest(&plant, now)
 
 	// Save changes in a transaction
 	tx := h.db.DB.Begin()
-	if err := tx.Save(&user).Error; err != nil {
+	balance, err := game.ApplyBalanceChange(tx, game.BalanceChange{
+		UserID:        user.ID,
+		Experience:    experienceEarned,
+		Reason:        models.LedgerHarvest,
+		ReferenceType: "plant",
+		ReferenceID:   plant.ID,
+		Note:          plant.PlantType.Name,
+	})
+	if err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
@@ -564,21 +734,189 @@ func (h *GardenHandler) HarvestPlant(c *gin.Context) {
 		return
 	}
 
+	if err := game.AddToInventory(tx, user.ID, plant.PlantTypeID, grade.Quality, itemsHarvested); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update inventory"})
+		return
+	}
+
+	if err := tx.Create(&record).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record harvest"})
+		return
+	}
+
 	tx.Commit()
 
+	h.gameEngine.PublishEvent(game.NewHarvestCompletedEvent(user.ID, &plant, game.HarvestEventData{
+		ItemsHarvested:   itemsHarvested,
+		Quality:          grade.Quality,
+		ExperienceEarned: experienceEarned,
+		NewLevel:         balance.Level,
+		LevelUp:          balance.LevelUp(),
+		Regrowing:        regrowing,
+	}))
+	if balance.LevelUp() {
+		h.gameEngine.PublishEvent(game.NewLevelChangedEvent(user.ID, balance.PreviousLevel, balance.Level))
+	}
+
 	response := gin.H{
 		"plant": plant,
 		"harvest": gin.H{
-			"coins_earned":      coinsEarned,
+			"record_id":         record.ID,
+			"items_harvested":   itemsHarvested,
+			"plant_type_id":     plant.PlantTypeID,
+			"quality":           grade.Quality,
+			"quality_score":     grade.Score,
+			"item_value":        record.ItemValue,
+			"freshness":         freshness,
 			"experience_earned": experienceEarned,
-			"level_up":          user.Level > oldLevel,
-			"new_level":         user.Level,
+			"level_up":          balance.LevelUp(),
+			"new_level":         balance.Level,
+			"harvest_number":    record.HarvestNumber,
+			"regrowing":         regrowing,
+			"harvests_left":     game.HarvestsLeft(&plant),
 		},
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// RevivePlant godoc
+// @Summary Revive a withered plant
+// @Description Bring a plant that died recently back to life, paying with coins or with harvested goods of its own type. Harvested and spoiled plants can't be revived.
+// @Tags plants
+// @Accept json
+// @Produce json
+// @Security bearer
+// @Param id path string true "Garden ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param plantId path string true "Plant ID" example("123e4567-e89b-12d3-a456-426614174001")
+// @Param request body RevivePlantRequest true "How to pay"
+// @Success 200 {object} map[string]interface{} "Revived plant"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Plant can't be revived"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 402 {object} map[string]interface{} "Not enough coins (code insufficient_coins)"
+// @Failure 404 {object} map[string]interface{} "Plant not found"
+// @Failure 409 {object} map[string]interface{} "Not enough items (code not_enough_items)"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /gardens/{id}/plants/{plantId}/revive [post]
+func (h *GardenHandler) RevivePlant(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	gardenID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid garden ID"})
+		return
+	}
+
+	plantID, err := uuid.Parse(c.Param("plantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plant ID"})
+		return
+	}
+
+	var req RevivePlantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Quality == "" {
+		req.Quality = models.QualityNormal
+	}
+
+	// Check if plant exists and belongs to user's garden
+	var plant models.Plant
+	if err := h.db.DB.Joins("JOIN gardens ON plants.garden_id = gardens.id").
+		Preload("PlantType").
+		Where("plants.id = ? AND gardens.id = ? AND gardens.user_id = ?", plantID, gardenID, userID).
+		First(&plant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Plant not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plant"})
+		return
+	}
+
+	// Apply elapsed growth so a plant that died since the last read is seen as withered
+	if err := h.gameEngine.MaterializePlant(&plant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update plant"})
+		return
+	}
+
+	now := time.Now()
+	revival, err := game.Revival(&plant, now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Plant can't be revived: %v", err)})
+		return
+	}
+
+	// Pay and revive the plant in one transaction
+	tx := h.db.DB.Begin()
+	receipt := gin.H{"pay_with": req.PayWith}
+	if req.PayWith == game.RevivePayCoins {
+		if _, err := game.ApplyBalanceChange(tx, game.BalanceChange{
+			UserID:        userID.(uuid.UUID),
+			Coins:         -revival.Coins,
+			Reason:        models.LedgerPlantRevival,
+			ReferenceType: "plant",
+			ReferenceID:   plant.ID,
+			Note:          plant.PlantType.Name,
+		}); err != nil {
+			tx.Rollback()
+			if err == game.ErrInsufficientCoins {
+				c.JSON(http.StatusPaymentRequired, gin.H{
+					"error": fmt.Sprintf("Reviving a %s costs %d coins", plant.PlantType.Name, revival.Coins),
+					"code":  ErrCodeInsufficientCoins,
+					"price": revival.Coins,
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+			return
+		}
+		receipt["coins_spent"] = revival.Coins
+	} else {
+		if err := game.TakeFromInventory(tx, userID.(uuid.UUID), plant.PlantTypeID, req.Quality, revival.Items); err != nil {
+			tx.Rollback()
+			if err == game.ErrNotEnoughItems {
+				c.JSON(http.StatusConflict, gin.H{
+					"error": fmt.Sprintf("Reviving a %s takes %d %s quality %s", plant.PlantType.Name, revival.Items, req.Quality, plant.PlantType.Name),
+					"code":  ErrCodeNotEnoughItems,
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update inventory"})
+			return
+		}
+		receipt["items_spent"] = revival.Items
+		receipt["quality"] = req.Quality
+	}
+
+	deathReason := plant.DeathReason
+	h.gameEngine.RevivePlant(&plant, now)
+	if err := tx.Save(&plant).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revive plant"})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revive plant"})
+		return
+	}
+
+	h.gameEngine.RefreshCondition(&plant)
+	h.gameEngine.PublishEvent(game.NewPlantUpdatedEvent(userID.(uuid.UUID), game.UpdatePlantRevived, &plant))
+
+	receipt["death_reason"] = deathReason
+	c.JSON(http.StatusOK, gin.H{"plant": plant, "revival": receipt})
+}
+
 // RemovePlant godoc
 // @Summary Remove a plant
 // @Description Remove a plant from the garden
@@ -632,6 +970,8 @@ func (h *GardenHandler) RemovePlant(c *gin.Context) {
 		return
 	}
 
+	h.gameEngine.PublishEvent(game.NewPlantUpdatedEvent(userID.(uuid.UUID), game.UpdatePlantRemoved, &plant))
+
 	c.JSON(http.StatusOK, gin.H{"message": "Plant removed successfully"})
 }
 
@@ -653,16 +993,12 @@ func (h *GardenHandler) ListPlantTypes(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"plant_types": plantTypes})
 }
 
-// Helper function to calculate level from experience
-func calculateLevel(experience int) int {
-	// Simple level calculation: every 100 XP = 1 level
-	return (experience / 100) + 1
-}
-
-// Helper function to get minimum value
-func min(a, b int) int {
-	if a < b {
-		return a
+// insufficientCoins builds the error response for a seed the player can't afford
+func insufficientCoins(plantType models.PlantType, coins int) gin.H {
+	return gin.H{
+		"error": fmt.Sprintf("%s seeds cost %d coins", plantType.Name, plantType.SeedPrice),
+		"code":  ErrCodeInsufficientCoins,
+		"price": plantType.SeedPrice,
+		"coins": coins,
 	}
-	return b
 }
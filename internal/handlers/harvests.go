@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/my-garden/api/internal/database"
+	"github.com/my-garden/api/internal/models"
+	"gorm.io/gorm"
+)
+
+type HarvestHandler struct {
+	db *database.Database
+}
+
+func NewHarvestHandler(db *database.Database) *HarvestHandler {
+	return &HarvestHandler{db: db}
+}
+
+type HarvestQuery struct {
+	From        time.Time `form:"from" time_format:"2006-01-02" example:"2024-01-01"`
+	To          time.Time `form:"to" time_format:"2006-01-02" example:"2024-01-31"`
+	PlantTypeID string    `form:"plant_type_id" binding:"omitempty,uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Page        int       `form:"page" binding:"omitempty,min=1" example:"1"`
+	Limit       int       `form:"limit" binding:"omitempty,min=1,max=100" example:"50"`
+}
+
+// HarvestTotals sums up a set of harvests
+type HarvestTotals struct {
+	Harvests         int64   `json:"harvests"`
+	ItemsHarvested   int64   `json:"items_harvested"`
+	ItemValue        int64   `json:"item_value"`
+	ExperienceEarned int64   `json:"experience_earned"`
+	AvgGrowthSeconds float64 `json:"avg_growth_seconds"`
+}
+
+// PlantTypeHarvestTotals sums up the harvests of one plant type
+type PlantTypeHarvestTotals struct {
+	PlantTypeID   uuid.UUID `json:"plant_type_id"`
+	PlantTypeName string    `json:"plant_type_name"`
+	HarvestTotals
+}
+
+// WeeklyHarvestTotals sums up the harvests of one week, starting on Monday
+type WeeklyHarvestTotals struct {
+	WeekStart time.Time `json:"week_start"`
+	HarvestTotals
+}
+
+const harvestTotalsColumns = "COUNT(*) AS harvests, " +
+	"COALESCE(SUM(harvest_records.items_harvested), 0) AS items_harvested, " +
+	"COALESCE(SUM(harvest_records.item_value), 0) AS item_value, " +
+	"COALESCE(SUM(harvest_records.experience_earned), 0) AS experience_earned, " +
+	"COALESCE(AVG(harvest_records.growth_duration_seconds), 0) AS avg_growth_seconds"
+
+// bindHarvestQuery parses the filters of a harvest history request, responding with
+// an error if they are invalid
+func bindHarvestQuery(c *gin.Context) (HarvestQuery, bool) {
+	var query HarvestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return query, false
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return query, false
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 50
+	}
+	return query, true
+}
+
+// filter limits a query to the user's harvests matching the request
+func (q HarvestQuery) filter(userID interface{}) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("harvest_records.user_id = ?", userID)
+		if !q.From.IsZero() {
+			db = db.Where("harvest_records.harvested_at >= ?", q.From)
+		}
+		if !q.To.IsZero() {
+			// The to date is inclusive
+			db = db.Where("harvest_records.harvested_at < ?", q.To.AddDate(0, 0, 1))
+		}
+		if q.PlantTypeID != "" {
+			db = db.Where("harvest_records.plant_type_id = ?", q.PlantTypeID)
+		}
+		return db
+	}
+}
+
+// GetHarvests godoc
+// @Summary Get harvest history
+// @Description Get the current user's past harvests, newest first, with totals overall, per plant type and per week. Dates are inclusive.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security bearer
+// @Param from query string false "First day to include (YYYY-MM-DD)"
+// @Param to query string false "Last day to include (YYYY-MM-DD)"
+// @Param plant_type_id query string false "Only include harvests of this plant type"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Harvests per page (max 100)" default(50)
+// @Success 200 {object} map[string]interface{} "Harvest history and totals"
+// @Failure 400 {object} map[string]interface{} "Bad Request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/harvests [get]
+func (h *HarvestHandler) GetHarvests(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	query, ok := bindHarvestQuery(c)
+	if !ok {
+		return
+	}
+	filter := query.filter(userID)
+
+	records := []models.HarvestRecord{}
+	if err := h.db.DB.Scopes(filter).
+		Preload("PlantType").
+		Order("harvest_records.harvested_at DESC").
+		Offset((query.Page - 1) * query.Limit).
+		Limit(query.Limit).
+		Find(&records).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch harvests"})
+		return
+	}
+
+	var totals HarvestTotals
+	if err := h.db.DB.Model(&models.HarvestRecord{}).Scopes(filter).
+		Select(harvestTotalsColumns).
+		Scan(&totals).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to total harvests"})
+		return
+	}
+
+	byPlantType := []PlantTypeHarvestTotals{}
+	if err := h.db.DB.Model(&models.HarvestRecord{}).Scopes(filter).
+		Select("harvest_records.plant_type_id, plant_types.name AS plant_type_name, " + harvestTotalsColumns).
+		Joins("JOIN plant_types ON plant_types.id = harvest_records.plant_type_id").
+		Group("harvest_records.plant_type_id, plant_types.name").
+		Order("harvests DESC, plant_types.name").
+		Scan(&byPlantType).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to total harvests"})
+		return
+	}
+
+	byWeek := []WeeklyHarvestTotals{}
+	if err := h.db.DB.Model(&models.HarvestRecord{}).Scopes(filter).
+		Select("date_trunc('week', harvest_records.harvested_at) AS week_start, " + harvestTotalsColumns).
+		Group("week_start").
+		Order("week_start DESC").
+		Scan(&byWeek).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to total harvests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"harvests": records,
+		"page":     query.Page,
+		"limit":    query.Limit,
+		"total":    totals.Harvests,
+		"totals": gin.H{
+			"overall":       totals,
+			"by_plant_type": byPlantType,
+			"by_week":       byWeek,
+		},
+	})
+}
+
+// ExportHarvests godoc
+// @Summary Export harvest history as CSV
+// @Description Download every harvest matching the filters as a CSV file, oldest first
+// @Tags users
+// @Produce text/csv
+// @Security bearer
+// @Param from query string false "First day to include (YYYY-MM-DD)"
+// @Param to query string false "Last day to include (YYYY-MM-DD)"
+// @Param plant_type_id query string false "Only include harvests of this plant type"
+// @Success 200 {file} file "CSV file"
+// @Failure 400 {object} map[string]interface{} "Bad Request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/harvests/export [get]
+func (h *HarvestHandler) ExportHarvests(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	query, ok := bindHarvestQuery(c)
+	if !ok {
+		return
+	}
+
+	rows, err := h.db.DB.Model(&models.HarvestRecord{}).Scopes(query.filter(userID)).
+		Select("harvest_records.*, plant_types.name AS plant_type_name").
+		Joins("LEFT JOIN plant_types ON plant_types.id = harvest_records.plant_type_id").
+		Order("harvest_records.harvested_at").
+		Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch harvests"})
+		return
+	}
+	defer rows.Close()
+
+	filename := fmt.Sprintf("harvests-%s.csv", time.Now().Format("20060102"))
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	defer w.Flush()
+	w.Write([]string{
+		"harvested_at", "plant_type", "garden_id", "position", "planted_at", "growth_hours",
+		"health", "water_level", "fertilizer_level", "weather", "temperature", "season",
+		"items_harvested", "item_value", "experience_earned", "quality", "quality_score",
+	})
+
+	// Stream rows so large histories don't have to fit in memory
+	for rows.Next() {
+		var record harvestExportRow
+		if err := h.db.DB.ScanRows(rows, &record); err != nil {
+			// Headers are already sent, so all we can do is cut the file short
+			log.Printf("Failed to export harvests for user %v: %v", userID, err)
+			return
+		}
+
+		temperature := ""
+		if record.Temperature != nil {
+			temperature = strconv.FormatFloat(*record.Temperature, 'f', 1, 64)
+		}
+		w.Write([]string{
+			record.HarvestedAt.UTC().Format(time.RFC3339),
+			record.PlantTypeName,
+			record.GardenID.String(),
+			strconv.Itoa(record.Position),
+			record.PlantedAt.UTC().Format(time.RFC3339),
+			strconv.FormatFloat(float64(record.GrowthDurationSeconds)/3600, 'f', 2, 64),
+			strconv.FormatFloat(record.Health, 'f', 1, 64),
+			strconv.FormatFloat(record.WaterLevel, 'f', 1, 64),
+			strconv.FormatFloat(record.FertilizerLevel, 'f', 1, 64),
+			string(record.WeatherCondition),
+			temperature,
+			string(record.Season),
+			strconv.Itoa(record.ItemsHarvested),
+			strconv.Itoa(record.ItemValue),
+			strconv.Itoa(record.ExperienceEarned),
+			string(record.Quality),
+			strconv.FormatFloat(record.QualityScore, 'f', 1, 64),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Failed to export harvests for user %v: %v", userID, err)
+	}
+}
+
+// harvestExportRow is a harvest record with its plant type's name
+type harvestExportRow struct {
+	models.HarvestRecord
+	PlantTypeName string
+}
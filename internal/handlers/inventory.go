@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/my-garden/api/internal/database"
+	"github.com/my-garden/api/internal/models"
+	"github.com/my-garden/api/pkg/game"
+	"gorm.io/gorm"
+)
+
+type InventoryHandler struct {
+	db         *database.Database
+	gameEngine *game.GameEngine
+}
+
+func NewInventoryHandler(db *database.Database, gameEngine *game.GameEngine) *InventoryHandler {
+	return &InventoryHandler{
+		db:         db,
+		gameEngine: gameEngine,
+	}
+}
+
+type InventoryRequest struct {
+	Quantity int                   `json:"quantity" binding:"required,min=1" example:"3"`
+	Quality  models.HarvestQuality `json:"quality" binding:"omitempty,oneof=poor normal good excellent" example:"good"` // defaults to normal
+}
+
+// InventoryEntry is an inventory item along with what it would sell for
+type InventoryEntry struct {
+	models.InventoryItem
+	UnitPrice int `json:"unit_price"`
+	Value     int `json:"value"`
+}
+
+// GetInventory godoc
+// @Summary Get inventory
+// @Description Get the harvested goods the current user owns, stacked by plant type and quality, and what they would sell for
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security bearer
+// @Success 200 {object} map[string]interface{} "Inventory items"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /inventory [get]
+func (h *InventoryHandler) GetInventory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var items []models.InventoryItem
+	if err := h.db.DB.Preload("PlantType").
+		Where("user_id = ? AND quantity > 0", userID).
+		Order("updated_at DESC, quality").
+		Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inventory"})
+		return
+	}
+
+	entries := make([]InventoryEntry, 0, len(items))
+	totalValue := 0
+	for _, item := range items {
+		entry := InventoryEntry{
+			InventoryItem: item,
+			UnitPrice:     game.SalePrice(&item.PlantType, item.Quality, 1),
+			Value:         game.SalePrice(&item.PlantType, item.Quality, item.Quantity),
+		}
+		totalValue += entry.Value
+		entries = append(entries, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":       entries,
+		"total_value": totalValue,
+	})
+}
+
+// SellItems godoc
+// @Summary Sell harvested goods
+// @Description Sell goods of one plant type and quality from the inventory for coins. Better quality goods sell for more.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security bearer
+// @Param plantTypeId path string true "Plant type ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param request body InventoryRequest true "Quantity and quality to sell"
+// @Success 200 {object} map[string]interface{} "Sale results"
+// @Failure 400 {object} map[string]interface{} "Bad Request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Plant type not found"
+// @Failure 409 {object} map[string]interface{} "Not enough items (code not_enough_items)"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /inventory/{plantTypeId}/sell [post]
+func (h *InventoryHandler) SellItems(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	plantType, req, ok := h.bindItemRequest(c)
+	if !ok {
+		return
+	}
+
+	coinsEarned := game.SalePrice(plantType, req.Quality, req.Quantity)
+
+	// Take the goods and pay for them in one transaction
+	tx := h.db.DB.Begin()
+	if err := game.TakeFromInventory(tx, userID.(uuid.UUID), plantType.ID, req.Quality, req.Quantity); err != nil {
+		tx.Rollback()
+		h.itemError(c, plantType, req.Quality, err)
+		return
+	}
+
+	balance, err := game.ApplyBalanceChange(tx, game.BalanceChange{
+		UserID:        userID.(uuid.UUID),
+		Coins:         coinsEarned,
+		Reason:        models.LedgerItemSale,
+		ReferenceType: "plant_type",
+		ReferenceID:   plantType.ID,
+		Note:          fmt.Sprintf("%d %s %s", req.Quantity, req.Quality, plantType.Name),
+	})
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sell items"})
+		return
+	}
+
+	remaining, err := h.remaining(userID, plantType.ID, req.Quality)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inventory"})
+		return
+	}
+
+	h.gameEngine.PublishEvent(game.NewItemsSoldEvent(userID.(uuid.UUID), game.InventoryEventData{
+		PlantTypeID: plantType.ID,
+		Quality:     req.Quality,
+		Quantity:    req.Quantity,
+		Remaining:   remaining,
+		CoinsEarned: coinsEarned,
+	}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"sale": gin.H{
+			"plant_type_id": plantType.ID,
+			"quality":       req.Quality,
+			"quantity":      req.Quantity,
+			"coins_earned":  coinsEarned,
+			"remaining":     remaining,
+			"coins":         balance.Coins,
+		},
+	})
+}
+
+// ConsumeItems godoc
+// @Summary Consume harvested goods
+// @Description Use up goods of one plant type and quality from the inventory. Every item consumed is worth a little experience.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security bearer
+// @Param plantTypeId path string true "Plant type ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param request body InventoryRequest true "Quantity and quality to consume"
+// @Success 200 {object} map[string]interface{} "Consumption results"
+// @Failure 400 {object} map[string]interface{} "Bad Request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Plant type not found"
+// @Failure 409 {object} map[string]interface{} "Not enough items (code not_enough_items)"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /inventory/{plantTypeId}/consume [post]
+func (h *InventoryHandler) ConsumeItems(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	plantType, req, ok := h.bindItemRequest(c)
+	if !ok {
+		return
+	}
+
+	experienceEarned := game.ConsumeExperience * req.Quantity
+
+	tx := h.db.DB.Begin()
+	if err := game.TakeFromInventory(tx, userID.(uuid.UUID), plantType.ID, req.Quality, req.Quantity); err != nil {
+		tx.Rollback()
+		h.itemError(c, plantType, req.Quality, err)
+		return
+	}
+
+	balance, err := game.ApplyBalanceChange(tx, game.BalanceChange{
+		UserID:        userID.(uuid.UUID),
+		Experience:    experienceEarned,
+		Reason:        models.LedgerItemConsumption,
+		ReferenceType: "plant_type",
+		ReferenceID:   plantType.ID,
+		Note:          fmt.Sprintf("%d %s %s", req.Quantity, req.Quality, plantType.Name),
+	})
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to consume items"})
+		return
+	}
+
+	remaining, err := h.remaining(userID, plantType.ID, req.Quality)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inventory"})
+		return
+	}
+
+	h.gameEngine.PublishEvent(game.NewItemsConsumedEvent(userID.(uuid.UUID), game.InventoryEventData{
+		PlantTypeID:      plantType.ID,
+		Quality:          req.Quality,
+		Quantity:         req.Quantity,
+		Remaining:        remaining,
+		ExperienceEarned: experienceEarned,
+	}))
+	if balance.LevelUp() {
+		h.gameEngine.PublishEvent(game.NewLevelChangedEvent(userID.(uuid.UUID), balance.PreviousLevel, balance.Level))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"consumed": gin.H{
+			"plant_type_id":     plantType.ID,
+			"quality":           req.Quality,
+			"quantity":          req.Quantity,
+			"experience_earned": experienceEarned,
+			"level_up":          balance.LevelUp(),
+			"new_level":         balance.Level,
+			"remaining":         remaining,
+		},
+	})
+}
+
+// bindItemRequest parses the plant type and quantity of a sell or consume request,
+// responding with an error if either is invalid
+func (h *InventoryHandler) bindItemRequest(c *gin.Context) (*models.PlantType, InventoryRequest, bool) {
+	var req InventoryRequest
+
+	plantTypeID, err := uuid.Parse(c.Param("plantTypeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plant type ID"})
+		return nil, req, false
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, req, false
+	}
+	if req.Quality == "" {
+		req.Quality = models.QualityNormal
+	}
+
+	var plantType models.PlantType
+	if err := h.db.DB.First(&plantType, plantTypeID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Plant type not found"})
+			return nil, req, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plant type"})
+		return nil, req, false
+	}
+
+	return &plantType, req, true
+}
+
+// itemError responds to a failure to take goods from the inventory
+func (h *InventoryHandler) itemError(c *gin.Context, plantType *models.PlantType, quality models.HarvestQuality, err error) {
+	if err == game.ErrNotEnoughItems {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": fmt.Sprintf("Not enough %s quality %s in inventory", quality, plantType.Name),
+			"code":  ErrCodeNotEnoughItems,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update inventory"})
+}
+
+// remaining returns how many of a plant type's goods of some quality the user has left
+func (h *InventoryHandler) remaining(userID interface{}, plantTypeID uuid.UUID, quality models.HarvestQuality) (int, error) {
+	var item models.InventoryItem
+	if err := h.db.DB.Where("user_id = ? AND plant_type_id = ? AND quality = ?", userID, plantTypeID, quality).First(&item).Error; err != nil {
+		return 0, err
+	}
+	return item.Quantity, nil
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/my-garden/api/internal/models"
+	"github.com/my-garden/api/pkg/game"
+	"gorm.io/gorm"
+)
+
+type PurchaseUpgradeRequest struct {
+	Upgrade game.UpgradeType `json:"upgrade" binding:"required,oneof=sprinkler greenhouse composter" example:"sprinkler"`
+}
+
+type ExpandGardenRequest struct {
+	Rows    int `json:"rows" binding:"min=0,max=7" example:"1"`
+	Columns int `json:"columns" binding:"min=0,max=7" example:"0"`
+}
+
+// ListUpgrades godoc
+// @Summary List garden upgrades
+// @Description List the upgrades available for a garden, with their costs and whether they are installed
+// @Tags gardens
+// @Accept json
+// @Produce json
+// @Security bearer
+// @Param id path string true "Garden ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Success 200 {object} map[string]interface{} "Garden upgrades"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid garden ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Garden not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /gardens/{id}/upgrades [get]
+func (h *GardenHandler) ListUpgrades(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	gardenID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid garden ID"})
+		return
+	}
+
+	var garden models.Garden
+	if err := h.db.DB.Where("id = ? AND user_id = ?", gardenID, userID).First(&garden).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Garden not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch garden"})
+		return
+	}
+
+	upgrades := make([]gin.H, 0, len(game.Upgrades))
+	for _, upgrade := range game.Upgrades {
+		upgrades = append(upgrades, gin.H{
+			"type":        upgrade.Type,
+			"name":        upgrade.Name,
+			"description": upgrade.Description,
+			"cost":        upgrade.Cost,
+			"min_level":   upgrade.MinLevel,
+			"installed":   upgrade.Installed(&garden),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"upgrades": upgrades})
+}
+
+// PurchaseUpgrade godoc
+// @Summary Buy a garden upgrade
+// @Description Buy a sprinkler, greenhouse or composter for a garden
+// @Tags gardens
+// @Accept json
+// @Produce json
+// @Security bearer
+// @Param id path string true "Garden ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param request body PurchaseUpgradeRequest true "Upgrade to buy"
+// @Success 200 {object} map[string]interface{} "Upgraded garden"
+// @Failure 400 {object} map[string]interface{} "Bad Request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 402 {object} map[string]interface{} "Insufficient coins (code insufficient_coins)"
+// @Failure 403 {object} map[string]interface{} "Level too low (code level_too_low)"
+// @Failure 404 {object} map[string]interface{} "Garden not found"
+// @Failure 409 {object} map[string]interface{} "Upgrade already installed"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /gardens/{id}/upgrades [post]
+func (h *GardenHandler) PurchaseUpgrade(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	gardenID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid garden ID"})
+		return
+	}
+
+	var req PurchaseUpgradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	upgrade, ok := game.GetUpgrade(req.Upgrade)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown upgrade"})
+		return
+	}
+
+	// Check if garden exists and belongs to user
+	var garden models.Garden
+	if err := h.db.DB.Where("id = ? AND user_id = ?", gardenID, userID).First(&garden).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Garden not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch garden"})
+		return
+	}
+
+	if upgrade.Installed(&garden) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Garden already has a %s", upgrade.Type)})
+		return
+	}
+
+	var user models.User
+	if err := h.db.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	if user.Level < upgrade.MinLevel {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":          fmt.Sprintf("The %s requires level %d", upgrade.Name, upgrade.MinLevel),
+			"code":           ErrCodeLevelTooLow,
+			"required_level": upgrade.MinLevel,
+			"level":          user.Level,
+		})
+		return
+	}
+	if user.Coins < upgrade.Cost {
+		c.JSON(http.StatusPaymentRequired, insufficientCoinsForUpgrade(upgrade, user.Coins))
+		return
+	}
+
+	// Settle growth under the old setup before the upgrade changes it
+	if err := h.db.DB.Where("garden_id = ?", garden.ID).Preload("PlantType").Find(&garden.Plants).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plants"})
+		return
+	}
+	if err := h.gameEngine.MaterializeGarden(&garden); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update plants"})
+		return
+	}
+
+	// Pay for the upgrade and install it in one transaction
+	tx := h.db.DB.Begin()
+	if _, err := game.ApplyBalanceChange(tx, game.BalanceChange{
+		UserID:        user.ID,
+		Coins:         -upgrade.Cost,
+		Reason:        models.LedgerUpgradePurchase,
+		ReferenceType: "garden",
+		ReferenceID:   garden.ID,
+		Note:          string(upgrade.Type),
+	}); err != nil {
+		tx.Rollback()
+		if err == game.ErrInsufficientCoins {
+			c.JSON(http.StatusPaymentRequired, insufficientCoinsForUpgrade(upgrade, user.Coins))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	result := tx.Model(&models.Garden{}).
+		Where("id = ? AND "+upgrade.Column()+" = ?", garden.ID, false).
+		Update(upgrade.Column(), true)
+	if result.Error != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to install upgrade"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		// Bought by a concurrent request
+		tx.Rollback()
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Garden already has a %s", upgrade.Type)})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to install upgrade"})
+		return
+	}
+
+	if err := h.db.DB.First(&garden, garden.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch garden"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"garden": garden, "coins_spent": upgrade.Cost})
+}
+
+// ExpandGarden godoc
+// @Summary Expand a garden
+// @Description Buy extra rows and columns for a garden. Every added plot costs coins. Adding columns renumbers existing plants so they keep their row and column.
+// @Tags gardens
+// @Accept json
+// @Produce json
+// @Security bearer
+// @Param id path string true "Garden ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param request body ExpandGardenRequest true "Rows and columns to add"
+// @Success 200 {object} map[string]interface{} "Expanded garden"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Nothing to add or garden would exceed its maximum size"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 402 {object} map[string]interface{} "Insufficient coins (code insufficient_coins)"
+// @Failure 404 {object} map[string]interface{} "Garden not found"
+// @Failure 409 {object} map[string]interface{} "Garden changed during expansion"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /gardens/{id}/expand [post]
+func (h *GardenHandler) ExpandGarden(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	gardenID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid garden ID"})
+		return
+	}
+
+	var req ExpandGardenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Rows == 0 && req.Columns == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Add at least one row or column"})
+		return
+	}
+
+	// Check if garden exists and belongs to user
+	var garden models.Garden
+	if err := h.db.DB.Where("id = ? AND user_id = ?", gardenID, userID).First(&garden).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Garden not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch garden"})
+		return
+	}
+
+	if !game.CanExpand(&garden, req.Rows, req.Columns) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Gardens can be at most %dx%d", game.MaxGardenWidth, game.MaxGardenHeight)})
+		return
+	}
+
+	cost := game.ExpansionCost(&garden, req.Rows, req.Columns)
+	insufficient := gin.H{
+		"error": fmt.Sprintf("This expansion costs %d coins", cost),
+		"code":  ErrCodeInsufficientCoins,
+		"price": cost,
+	}
+
+	width, height := garden.Width+req.Columns, garden.Height+req.Rows
+
+	// Pay for the plots and resize the garden in one transaction
+	tx := h.db.DB.Begin()
+	if _, err := game.ApplyBalanceChange(tx, game.BalanceChange{
+		UserID:        garden.UserID,
+		Coins:         -cost,
+		Reason:        models.LedgerGardenExpansion,
+		ReferenceType: "garden",
+		ReferenceID:   garden.ID,
+		Note:          fmt.Sprintf("%dx%d to %dx%d", garden.Width, garden.Height, width, height),
+	}); err != nil {
+		tx.Rollback()
+		if err == game.ErrInsufficientCoins {
+			c.JSON(http.StatusPaymentRequired, insufficient)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	result := tx.Model(&models.Garden{}).
+		Where("id = ? AND width = ? AND height = ?", garden.ID, garden.Width, garden.Height).
+		Updates(map[string]interface{}{"width": width, "height": height, "size": width * height})
+	if result.Error != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to expand garden"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, gin.H{"error": "Garden was resized by another request"})
+		return
+	}
+
+	// Positions are numbered row by row, so wider rows shift every plant after the first row
+	if req.Columns > 0 {
+		if err := tx.Model(&models.Plant{}).Where("garden_id = ?", garden.ID).
+			UpdateColumn("position", gorm.Expr("(position / ?) * ? + position % ?", garden.Width, width, garden.Width)).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move plants"})
+			return
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to expand garden"})
+		return
+	}
+
+	if err := h.db.DB.Preload("Plants.PlantType").First(&garden, garden.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch garden"})
+		return
+	}
+
+	if req.Columns > 0 {
+		for i := range garden.Plants {
+			h.gameEngine.PublishEvent(game.NewPlantUpdatedEvent(garden.UserID, game.UpdatePlantMoved, &garden.Plants[i]))
+		}
+	}
+
+	if err := h.gameEngine.MaterializeGarden(&garden); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update plants"})
+		return
+	}
+	game.DescribeGrid(&garden)
+
+	c.JSON(http.StatusOK, gin.H{"garden": garden, "coins_spent": cost})
+}
+
+// insufficientCoinsForUpgrade builds the error response for an upgrade the player can't afford
+func insufficientCoinsForUpgrade(upgrade game.Upgrade, coins int) gin.H {
+	return gin.H{
+		"error": fmt.Sprintf("The %s costs %d coins", upgrade.Name, upgrade.Cost),
+		"code":  ErrCodeInsufficientCoins,
+		"price": upgrade.Cost,
+		"coins": coins,
+	}
+}
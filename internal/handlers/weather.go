@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/my-garden/api/internal/database"
 	"github.com/my-garden/api/internal/models"
 	"github.com/my-garden/api/pkg/game"
@@ -29,6 +30,7 @@ func NewWeatherHandler(db *database.Database, gameEngine *game.GameEngine) *Weat
 // @Tags weather
 // @Accept json
 // @Produce json
+// @Security bearer
 // @Success 200 {object} map[string]interface{} "Current weather and season"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /weather/current [get]
@@ -54,28 +56,51 @@ func (h *WeatherHandler) GetCurrentWeather(c *gin.Context) {
 		"updated_at": weather.CreatedAt,
 	}
 
+	h.recordLookup(c, "current")
+
 	c.JSON(http.StatusOK, response)
 }
 
+// ForecastQuery limits how far ahead a forecast looks
+type ForecastQuery struct {
+	Hours int `form:"hours" binding:"omitempty,min=1,max=168" example:"24"`
+}
+
 // GetWeatherForecast godoc
 // @Summary Get weather forecast
-// @Description Get weather predictions for next 24 hours
+// @Description Get the weather the game has drawn for the coming hours, with the model's confidence in each condition and warnings of extreme weather on the way
 // @Tags weather
 // @Accept json
 // @Produce json
+// @Security bearer
+// @Param hours query int false "How many hours ahead to forecast (max 168)" default(24)
 // @Success 200 {object} map[string]interface{} "Weather forecasts"
+// @Failure 400 {object} map[string]interface{} "Bad Request"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /weather/forecast [get]
 func (h *WeatherHandler) GetWeatherForecast(c *gin.Context) {
-	// Get forecast for next 24 hours (4 periods of 6 hours each)
-	var forecasts []models.WeatherForecast
+	var query ForecastQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if query.Hours == 0 {
+		query.Hours = 24
+	}
+
+	now := time.Now()
+	forecasts, warnings, err := h.gameEngine.GetForecast(now, now.Add(time.Duration(query.Hours)*time.Hour))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch weather forecast"})
+		return
+	}
 
-	// Generate forecast data (in a real app, this would use a weather API)
-	forecasts = h.generateForecast()
+	h.recordLookup(c, "forecast")
 
 	c.JSON(http.StatusOK, gin.H{
 		"forecasts":    forecasts,
-		"generated_at": time.Now(),
+		"warnings":     warnings,
+		"generated_at": now,
 	})
 }
 
@@ -85,6 +110,7 @@ func (h *WeatherHandler) GetWeatherForecast(c *gin.Context) {
 // @Tags weather
 // @Accept json
 // @Produce json
+// @Security bearer
 // @Success 200 {object} map[string]interface{} "Weather history"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /weather/history [get]
@@ -97,78 +123,17 @@ func (h *WeatherHandler) GetWeatherHistory(c *gin.Context) {
 		return
 	}
 
+	h.recordLookup(c, "history")
+
 	c.JSON(http.StatusOK, gin.H{
 		"history": weatherHistory,
 		"count":   len(weatherHistory),
 	})
 }
 
-// generateForecast creates a simple weather forecast
-func (h *WeatherHandler) generateForecast() []models.WeatherForecast {
-	var forecasts []models.WeatherForecast
-
-	// Get current weather to base forecast on
-	currentWeather, err := h.gameEngine.GetCurrentWeather()
-	if err != nil {
-		// Use default weather if current weather not available
-		currentWeather = &models.Weather{
-			Condition:   models.WeatherCloudy,
-			Temperature: 20.0,
-			Humidity:    50,
-		}
-	}
-
-	// Generate 4 forecast periods (6 hours each)
-	for i := 1; i <= 4; i++ {
-		forecastTime := time.Now().Add(time.Duration(i*6) * time.Hour)
-
-		// Simple forecast logic - vary conditions slightly
-		condition := currentWeather.Condition
-		temperature := currentWeather.Temperature
-		humidity := currentWeather.Humidity
-
-		// Add some variation based on time of day
-		hour := forecastTime.Hour()
-		switch {
-		case hour >= 6 && hour <= 12: // Morning
-			temperature += 2
-			humidity = max(30, humidity-10)
-		case hour >= 13 && hour <= 18: // Afternoon
-			temperature += 5
-			humidity = max(20, humidity-20)
-		case hour >= 19 && hour <= 23: // Evening
-			temperature -= 2
-			humidity = minInt(80, humidity+10)
-		default: // Night
-			temperature -= 5
-			humidity = minInt(90, humidity+20)
-		}
-
-		forecast := models.WeatherForecast{
-			Condition:   condition,
-			Temperature: temperature,
-			Humidity:    humidity,
-			Probability: 85, // 85% confidence
-			ForecastFor: forecastTime,
-		}
-
-		forecasts = append(forecasts, forecast)
-	}
-
-	return forecasts
-}
-
-// Helper function
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
-func minInt(a, b int) int {
-	if a < b {
-		return a
+// recordLookup lets the game know a signed-in player checked the weather
+func (h *WeatherHandler) recordLookup(c *gin.Context, lookup string) {
+	if userID, exists := c.Get("user_id"); exists {
+		h.gameEngine.PublishEvent(game.NewWeatherCheckedEvent(userID.(uuid.UUID), lookup))
 	}
-	return b
 }
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/my-garden/api/internal/config"
+	"github.com/my-garden/api/internal/database"
+	"github.com/my-garden/api/internal/models"
+	"github.com/my-garden/api/pkg/game"
+	"gorm.io/gorm"
+)
+
+const (
+	// Time allowed to write a message to the client
+	wsWriteWait = 10 * time.Second
+
+	// Time allowed to read the next pong message from the client
+	wsPongWait = 60 * time.Second
+
+	// Send pings to the client with this period. Must be less than wsPongWait.
+	wsPingPeriod = (wsPongWait * 9) / 10
+
+	// Maximum message size allowed from the client
+	wsMaxMessageSize = 512
+)
+
+type WebSocketHandler struct {
+	db         *database.Database
+	gameEngine *game.GameEngine
+	upgrader   websocket.Upgrader
+}
+
+func NewWebSocketHandler(db *database.Database, gameEngine *game.GameEngine, cfg *config.Config) *WebSocketHandler {
+	allowedOrigin := cfg.API.CORSOrigin
+
+	return &WebSocketHandler{
+		db:         db,
+		gameEngine: gameEngine,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				// Non-browser clients don't send an Origin header
+				return origin == "" || origin == allowedOrigin
+			},
+		},
+	}
+}
+
+// GardenUpdates godoc
+// @Summary Subscribe to garden updates
+// @Description Upgrade to a WebSocket that streams plant and weather changes for a garden. Pass the JWT in the Authorization header or the token query parameter.
+// @Tags websocket
+// @Security bearer
+// @Param gardenId path string true "Garden ID" example("123e4567-e89b-12d3-a456-426614174000")
+// @Param token query string false "JWT for clients that cannot set headers"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid garden ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Garden not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /ws/garden/{gardenId} [get]
+func (h *WebSocketHandler) GardenUpdates(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	gardenID, err := uuid.Parse(c.Param("gardenId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid garden ID"})
+		return
+	}
+
+	// Check if garden exists and belongs to user
+	var garden models.Garden
+	if err := h.db.DB.Where("id = ? AND user_id = ?", gardenID, userID).First(&garden).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Garden not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch garden"})
+		return
+	}
+
+	// Subscribe before taking the snapshot so no update falls in between
+	sub := h.gameEngine.Subscribe(gardenID)
+	defer h.gameEngine.Unsubscribe(sub)
+
+	if err := h.db.DB.Where("garden_id = ?", gardenID).Preload("PlantType").Find(&garden.Plants).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch plants"})
+		return
+	}
+
+	// Catch plants up with the time that passed since they were last evaluated
+	if err := h.gameEngine.MaterializeGarden(&garden); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update plants"})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already written an HTTP error response
+		log.Printf("WebSocket upgrade failed for garden %s: %v", gardenID, err)
+		return
+	}
+	defer conn.Close()
+
+	// Send the current state so clients don't need a separate GET
+	snapshot := make([]game.PlantDelta, 0, len(garden.Plants))
+	for i := range garden.Plants {
+		snapshot = append(snapshot, game.NewPlantDelta(&garden.Plants[i]))
+	}
+	if err := h.writeUpdate(conn, game.GardenUpdate{Type: game.UpdateGardenSnapshot, Data: snapshot}); err != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go h.readLoop(conn, done)
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case update, ok := <-sub.Updates():
+			if !ok {
+				conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := h.writeUpdate(conn, update); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLoop drains client frames so control messages are processed, and signals
+// done when the connection goes away
+func (h *WebSocketHandler) readLoop(conn *websocket.Conn, done chan<- struct{}) {
+	defer close(done)
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("WebSocket read error: %v", err)
+			}
+			return
+		}
+	}
+}
+
+func (h *WebSocketHandler) writeUpdate(conn *websocket.Conn, update game.GardenUpdate) error {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return conn.WriteJSON(update)
+}
@@ -73,3 +73,37 @@ func OptionalAuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// WebSocketAuthMiddleware accepts the usual Authorization header, falling back to a
+// "token" query parameter because browsers cannot set headers on WebSocket handshakes
+func WebSocketAuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+	headerAuth := AuthMiddleware(jwtManager)
+
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			headerAuth(c)
+			return
+		}
+
+		tokenString := c.Query("token")
+		if tokenString == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header or token query parameter required"})
+			c.Abort()
+			return
+		}
+
+		claims, err := jwtManager.ValidateToken(tokenString)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		// Set user information in context
+		c.Set("user_id", claims.UserID)
+		c.Set("username", claims.Username)
+		c.Set("email", claims.Email)
+
+		c.Next()
+	}
+}
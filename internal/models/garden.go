@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,7 +15,9 @@ type Garden struct {
 	Description string    `json:"description"`
 
 	// Garden properties
-	Size            int `json:"size" gorm:"default:9"`             // 3x3 grid
+	Width           int `json:"width" gorm:"default:3"`            // grid columns
+	Height          int `json:"height" gorm:"default:3"`           // grid rows
+	Size            int `json:"size" gorm:"default:9"`             // width x height plots
 	SoilQuality     int `json:"soil_quality" gorm:"default:50"`    // 0-100
 	WaterLevel      int `json:"water_level" gorm:"default:50"`     // 0-100
 	FertilizerLevel int `json:"fertilizer_level" gorm:"default:0"` // 0-100
@@ -30,11 +33,33 @@ type Garden struct {
 	LastWateredAt    *time.Time `json:"last_watered_at"`
 	LastFertilizedAt *time.Time `json:"last_fertilized_at"`
 
+	// Computed for responses, not stored
+	Grid *GardenGrid `json:"grid,omitempty" gorm:"-"`
+
 	// Relationships
 	User   User    `json:"user" gorm:"foreignKey:UserID"`
 	Plants []Plant `json:"plants,omitempty" gorm:"foreignKey:GardenID"`
 }
 
+// GardenGrid describes the layout of a garden's plots. Positions are numbered row by
+// row, so the plot at row r and column c is at position r*width + c.
+type GardenGrid struct {
+	Width         int   `json:"width"`
+	Height        int   `json:"height"`
+	Size          int   `json:"size"`
+	Occupied      int   `json:"occupied"`
+	MaxWidth      int   `json:"max_width"`
+	MaxHeight     int   `json:"max_height"`
+	RowCost       int   `json:"row_cost"`    // coins to add a row, 0 when at max height
+	ColumnCost    int   `json:"column_cost"` // coins to add a column, 0 when at max width
+	FreePositions []int `json:"free_positions"`
+}
+
+// Contains reports whether a position lies inside the garden's grid
+func (g *Garden) Contains(position int) bool {
+	return position >= 0 && position < g.Width*g.Height
+}
+
 func (g *Garden) BeforeCreate(tx *gorm.DB) error {
 	if g.ID == uuid.Nil {
 		g.ID = uuid.New()
@@ -48,28 +73,86 @@ type Plant struct {
 	GardenID    uuid.UUID `json:"garden_id" gorm:"type:uuid;not null"`
 	PlantTypeID uuid.UUID `json:"plant_type_id" gorm:"type:uuid;not null"`
 
-	// Position in garden grid (0-8 for 3x3 grid)
+	// Position in garden grid, numbered row by row (row*width + column)
 	Position int `json:"position" gorm:"not null"`
 
 	// Plant state
-	Stage          PlantStage `json:"stage" gorm:"default:'seed'"`
-	Health         int        `json:"health" gorm:"default:100"`        // 0-100
-	WaterLevel     int        `json:"water_level" gorm:"default:50"`    // 0-100
-	GrowthProgress float64    `json:"growth_progress" gorm:"default:0"` // 0-100
+	Stage           PlantStage `json:"stage" gorm:"default:'seed'"`
+	Health          int        `json:"health" gorm:"default:100"`         // 0-100
+	WaterLevel      int        `json:"water_level" gorm:"default:50"`     // 0-100
+	FertilizerLevel int        `json:"fertilizer_level" gorm:"default:0"` // 0-100, used up as the plant grows
+	GrowthProgress  float64    `json:"growth_progress" gorm:"default:0"`  // 0-100
+	HarvestCount    int        `json:"harvest_count" gorm:"default:0"`    // times harvested so far
+
+	// The levels above to a fraction, as growth last left them
+	Exact PlantLevels `json:"-" gorm:"embedded;embeddedPrefix:exact_"`
+
+	// How the plant has been looked after so far, used to grade its harvest
+	Care CareHistory `json:"-" gorm:"embedded"`
 
 	// Timestamps
-	PlantedAt        time.Time  `json:"planted_at"`
-	LastWateredAt    *time.Time `json:"last_watered_at"`
-	LastFertilizedAt *time.Time `json:"last_fertilized_at"`
-	HarvestedAt      *time.Time `json:"harvested_at"`
-	CreatedAt        time.Time  `json:"created_at"`
-	UpdatedAt        time.Time  `json:"updated_at"`
+	PlantedAt        time.Time   `json:"planted_at"`
+	LastEvaluatedAt  time.Time   `json:"last_evaluated_at" gorm:"index;default:CURRENT_TIMESTAMP"` // state above is as of this time
+	LastWateredAt    *time.Time  `json:"last_watered_at"`
+	LastFertilizedAt *time.Time  `json:"last_fertilized_at"`
+	RipenedAt        *time.Time  `json:"ripened_at"`   // when the plant last became harvestable
+	HarvestedAt      *time.Time  `json:"harvested_at"` // latest harvest
+	WitheredAt       *time.Time  `json:"withered_at"`  // when the plant died
+	DeathReason      DeathReason `json:"death_reason,omitempty"`
+	RevivedAt        *time.Time  `json:"revived_at"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+
+	// Computed when the plant is evaluated, not stored
+	Condition *PlantCondition `json:"condition,omitempty" gorm:"-"`
+	Ripeness  *PlantRipeness  `json:"ripeness,omitempty" gorm:"-"`
+	Revival   *PlantRevival   `json:"revival,omitempty" gorm:"-"`
 
 	// Relationships
 	Garden    Garden    `json:"garden" gorm:"foreignKey:GardenID"`
 	PlantType PlantType `json:"plant_type" gorm:"foreignKey:PlantTypeID"`
 }
 
+// PlantLevels are a plant's health, water and fertilizer levels to a fraction. Growth
+// changes them a little at a time, so they are kept alongside the whole-number levels
+// to carry the fractions from one evaluation to the next.
+type PlantLevels struct {
+	Health     float64 `gorm:"default:0"`
+	Water      float64 `gorm:"default:0"`
+	Fertilizer float64 `gorm:"default:0"`
+}
+
+// Levels returns the plant's health, water and fertilizer levels to a fraction. A
+// level set directly since growth last changed it, such as by watering, no longer
+// rounds to its exact value and is taken as it is.
+func (p *Plant) Levels() (health, water, fertilizer float64) {
+	return exactLevel(p.Exact.Health, p.Health), exactLevel(p.Exact.Water, p.WaterLevel), exactLevel(p.Exact.Fertilizer, p.FertilizerLevel)
+}
+
+// SetLevels sets the plant's health, water and fertilizer levels to a fraction
+func (p *Plant) SetLevels(health, water, fertilizer float64) {
+	p.Exact = PlantLevels{Health: health, Water: water, Fertilizer: fertilizer}
+	p.Health = int(math.Round(health))
+	p.WaterLevel = int(math.Round(water))
+	p.FertilizerLevel = int(math.Round(fertilizer))
+}
+
+func exactLevel(exact float64, level int) float64 {
+	if int(math.Round(exact)) == level {
+		return exact
+	}
+	return float64(level)
+}
+
+// CareHistory accumulates the conditions a plant grew in. Each field is a number of
+// simulated minutes, so dividing by Minutes gives an average or a share of the time.
+type CareHistory struct {
+	Minutes           float64 `gorm:"column:care_minutes;default:0"`        // time simulated
+	HealthMinutes     float64 `gorm:"column:care_health_minutes;default:0"` // health integrated over time
+	IdealWaterMinutes float64 `gorm:"column:care_ideal_water_minutes;default:0"`
+	FedMinutes        float64 `gorm:"column:care_fed_minutes;default:0"`
+}
+
 func (p *Plant) BeforeCreate(tx *gorm.DB) error {
 	if p.ID == uuid.Nil {
 		p.ID = uuid.New()
@@ -77,6 +160,48 @@ func (p *Plant) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// DeathReason explains why a plant withered
+type DeathReason string
+
+const (
+	DeathDrought        DeathReason = "drought"         // ran out of water
+	DeathFrost          DeathReason = "frost"           // froze outside a greenhouse
+	DeathHeat           DeathReason = "heat"            // scorched by more heat than it tolerates
+	DeathStorm          DeathReason = "storm"           // battered by a storm outside a greenhouse
+	DeathHail           DeathReason = "hail"            // shredded by hail
+	DeathHeatwave       DeathReason = "heatwave"        // scorched in a heatwave
+	DeathFlood          DeathReason = "flood"           // drowned in a flood
+	DeathPests          DeathReason = "pests"           // weakened out of season and overrun by pests
+	DeathRootRot        DeathReason = "root_rot"        // waterlogged for too long
+	DeathFertilizerBurn DeathReason = "fertilizer_burn" // given far too much fertilizer
+	DeathSpoiled        DeathReason = "spoiled"         // left unharvested until it rotted
+	DeathHarvested      DeathReason = "harvested"       // picked for the last time
+)
+
+// PlantRevival tells players how to bring a withered plant back
+type PlantRevival struct {
+	RevivableUntil time.Time `json:"revivable_until"`
+	Coins          int       `json:"coins"` // coin cost
+	Items          int       `json:"items"` // cost in harvested goods of the plant's own type
+}
+
+// PlantRipeness counts down to a ripe plant spoiling
+type PlantRipeness struct {
+	Status              RipenessStatus `json:"status"`
+	Freshness           float64        `json:"freshness"` // share of its value the harvest is still worth, 0-1
+	FreshUntil          time.Time      `json:"fresh_until"`
+	SpoilsAt            time.Time      `json:"spoils_at"`
+	SecondsUntilSpoiled int64          `json:"seconds_until_spoiled"`
+}
+
+// RipenessStatus says whether a ripe plant is still at its best
+type RipenessStatus string
+
+const (
+	RipenessFresh    RipenessStatus = "fresh"    // full value
+	RipenessOverripe RipenessStatus = "overripe" // losing value
+)
+
 // PlantStage represents the growth stage of a plant
 type PlantStage string
 
@@ -97,19 +222,40 @@ type PlantType struct {
 	Icon        string    `json:"icon"`
 
 	// Growth properties
-	GrowthTime      int `json:"growth_time" gorm:"not null"`       // in minutes
-	WaterNeeds      int `json:"water_needs" gorm:"default:50"`     // 0-100
-	FertilizerNeeds int `json:"fertilizer_needs" gorm:"default:0"` // 0-100
+	GrowthTime      int    `json:"growth_time" gorm:"not null"`           // in minutes
+	WaterNeeds      int    `json:"water_needs" gorm:"default:50"`         // 0-100
+	FertilizerNeeds int    `json:"fertilizer_needs" gorm:"default:0"`     // 0-100
+	GrowthModel     string `json:"growth_model" gorm:"default:'default'"` // default, tree, herb, vine
 
 	// Harvest properties
 	Yield           int `json:"yield" gorm:"default:1"`            // items per harvest
 	HarvestValue    int `json:"harvest_value" gorm:"default:10"`   // coins per item
 	ExperienceValue int `json:"experience_value" gorm:"default:5"` // XP per harvest
 
+	// Perennials are harvested more than once, growing back from RegrowStage and
+	// RegrowProgress after each harvest but the last. Annuals have a MaxHarvests of 1.
+	MaxHarvests    int        `json:"max_harvests" gorm:"default:1"`
+	RegrowStage    PlantStage `json:"regrow_stage,omitempty"`
+	RegrowProgress float64    `json:"regrow_progress" gorm:"default:0"` // 0-100, should fall within RegrowStage
+
+	// Ripe plants keep their full value for RipeWindow minutes, then lose value over
+	// SpoilTime minutes and rot. A RipeWindow of 0 means the plant never spoils.
+	RipeWindow int `json:"ripe_window" gorm:"default:240"`
+	SpoilTime  int `json:"spoil_time" gorm:"default:240"`
+
+	// Temperatures in °C. Plants grow fastest between OptimalTempMin and OptimalTempMax,
+	// slow down towards FrostThreshold and HeatThreshold, and are damaged beyond them.
+	OptimalTempMin float64 `json:"optimal_temp_min" gorm:"default:15"`
+	OptimalTempMax float64 `json:"optimal_temp_max" gorm:"default:25"`
+	FrostThreshold float64 `json:"frost_threshold" gorm:"default:2"`
+	HeatThreshold  float64 `json:"heat_threshold" gorm:"default:32"`
+
 	// Requirements
-	MinLevel int    `json:"min_level" gorm:"default:1"`
-	Season   string `json:"season"`  // spring, summer, autumn, winter, all
-	Weather  string `json:"weather"` // sunny, cloudy, rainy, all
+	SeedPrice    int    `json:"seed_price" gorm:"default:5"` // coins per seed
+	MinLevel     int    `json:"min_level" gorm:"default:1"`
+	Season       string `json:"season"`                              // spring, summer, autumn, winter, all
+	Weather      string `json:"weather"`                             // sunny, cloudy, rainy, all
+	SeasonPolicy string `json:"season_policy" gorm:"default:'warn'"` // allow, warn or reject out-of-season planting
 
 	// Rarity
 	Rarity string `json:"rarity" gorm:"default:'common'"` // common, uncommon, rare, epic, legendary
@@ -118,9 +264,57 @@ type PlantType struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Season policies decide what happens when a plant type is planted out of season
+const (
+	SeasonPolicyAllow  = "allow"
+	SeasonPolicyWarn   = "warn"
+	SeasonPolicyReject = "reject"
+)
+
+// Perennial reports whether plants of this type can be harvested more than once
+func (pt *PlantType) Perennial() bool {
+	return pt.MaxHarvests > 1
+}
+
+// InSeason reports whether the plant type prefers the given season
+func (pt *PlantType) InSeason(season Season) bool {
+	return pt.Season == "" || pt.Season == "all" || Season(pt.Season) == season
+}
+
+// PrefersWeather reports whether the plant type prefers the given weather
+func (pt *PlantType) PrefersWeather(condition WeatherCondition) bool {
+	return pt.Weather == "" || pt.Weather == "all" || WeatherCondition(pt.Weather) == condition
+}
+
 func (pt *PlantType) BeforeCreate(tx *gorm.DB) error {
 	if pt.ID == uuid.Nil {
 		pt.ID = uuid.New()
 	}
 	return nil
 }
+
+// PlantStatus summarizes how well a plant is doing under current conditions
+type PlantStatus string
+
+const (
+	PlantStatusThriving   PlantStatus = "thriving"
+	PlantStatusHealthy    PlantStatus = "healthy"
+	PlantStatusStruggling PlantStatus = "struggling"
+)
+
+// PlantCondition explains why a plant is thriving or struggling
+type PlantCondition struct {
+	Status           PlantStatus       `json:"status"`
+	GrowthMultiplier float64           `json:"growth_multiplier"` // combined effect of all factors
+	HealthPerTick    float64           `json:"health_per_tick"`
+	Factors          []ConditionFactor `json:"factors"`
+}
+
+// ConditionFactor is one influence on a plant, such as the season or its water level
+type ConditionFactor struct {
+	Factor           string  `json:"factor"` // climate, season, weather, water, fertilizer
+	Effect           string  `json:"effect"` // bonus, penalty or neutral
+	Reason           string  `json:"reason"`
+	GrowthMultiplier float64 `json:"growth_multiplier"`
+	HealthPerTick    float64 `json:"health_per_tick"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// HarvestQuality grades how well a plant was looked after by the time it was harvested
+type HarvestQuality string
+
+const (
+	QualityPoor      HarvestQuality = "poor"
+	QualityNormal    HarvestQuality = "normal"
+	QualityGood      HarvestQuality = "good"
+	QualityExcellent HarvestQuality = "excellent"
+)
+
+// HarvestRecord keeps the history of a harvest after the plant itself is gone
+type HarvestRecord struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index:idx_harvest_user_time"`
+	GardenID    uuid.UUID `json:"garden_id" gorm:"type:uuid;not null;index"` // the garden may since have been deleted
+	PlantID     uuid.UUID `json:"plant_id" gorm:"type:uuid;not null;uniqueIndex:idx_harvest_plant_number"`
+	PlantTypeID uuid.UUID `json:"plant_type_id" gorm:"type:uuid;not null;index"`
+	Position    int       `json:"position"`
+
+	// Perennials are harvested more than once; this counts from 1
+	HarvestNumber int `json:"harvest_number" gorm:"not null;default:1;uniqueIndex:idx_harvest_plant_number"`
+
+	// Lifecycle
+	PlantedAt             time.Time `json:"planted_at"`
+	HarvestedAt           time.Time `json:"harvested_at" gorm:"not null;index:idx_harvest_user_time"`
+	GrowthDurationSeconds int64     `json:"growth_duration_seconds"` // since planting, or since the previous harvest
+
+	// Plant state at harvest
+	Health          float64 `json:"health"`
+	WaterLevel      float64 `json:"water_level"`
+	FertilizerLevel float64 `json:"fertilizer_level"`
+
+	// Conditions at harvest, unknown for harvests recorded before weather was kept
+	WeatherCondition WeatherCondition `json:"weather_condition,omitempty"`
+	Temperature      *float64         `json:"temperature,omitempty"`
+	Season           Season           `json:"season"`
+
+	// Rewards
+	ItemsHarvested   int `json:"items_harvested"`
+	ItemValue        int `json:"item_value"` // coins the harvested goods sell for
+	ExperienceEarned int `json:"experience_earned"`
+
+	// Quality the harvest was graded at, and the 0-100 score behind the grade
+	Quality      HarvestQuality `json:"quality" gorm:"not null;default:'normal'"`
+	QualityScore float64        `json:"quality_score"`
+
+	// Share of the yield that was still good, below 1 if the plant was left overripe
+	Freshness float64 `json:"freshness" gorm:"not null;default:1"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	PlantType PlantType `json:"plant_type" gorm:"foreignKey:PlantTypeID"`
+}
+
+func (h *HarvestRecord) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
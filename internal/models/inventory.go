@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InventoryItem is a stack of harvested goods a user owns, one per plant type and quality
+type InventoryItem struct {
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_inventory_user_plant_type_quality"`
+	PlantTypeID uuid.UUID      `json:"plant_type_id" gorm:"type:uuid;not null;uniqueIndex:idx_inventory_user_plant_type_quality"`
+	Quality     HarvestQuality `json:"quality" gorm:"not null;default:'normal';uniqueIndex:idx_inventory_user_plant_type_quality"`
+	Quantity    int            `json:"quantity" gorm:"not null;default:0"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+
+	// Relationships
+	User      User      `json:"-" gorm:"foreignKey:UserID"`
+	PlantType PlantType `json:"plant_type,omitempty" gorm:"foreignKey:PlantTypeID"`
+}
+
+func (i *InventoryItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LedgerCurrency is a balance tracked by the ledger
+type LedgerCurrency string
+
+const (
+	LedgerCoins      LedgerCurrency = "coins"
+	LedgerExperience LedgerCurrency = "experience"
+)
+
+// LedgerReason explains why a balance changed
+type LedgerReason string
+
+const (
+	LedgerOpeningBalance  LedgerReason = "opening_balance"
+	LedgerSeedPurchase    LedgerReason = "seed_purchase"
+	LedgerUpgradePurchase LedgerReason = "upgrade_purchase"
+	LedgerGardenExpansion LedgerReason = "garden_expansion"
+	LedgerHarvest         LedgerReason = "harvest"
+	LedgerItemSale        LedgerReason = "item_sale"
+	LedgerItemConsumption LedgerReason = "item_consumption"
+	LedgerPlantRevival    LedgerReason = "plant_revival"
+)
+
+// LedgerEntry records a single change to a user's coins or experience. Entries are
+// only ever appended, so a user's balance always equals the sum of their entries.
+type LedgerEntry struct {
+	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID        uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index:idx_ledger_user_created"`
+	Currency      LedgerCurrency `json:"currency" gorm:"not null"`
+	Amount        int            `json:"amount" gorm:"not null"`
+	BalanceAfter  int            `json:"balance_after" gorm:"not null"`
+	Reason        LedgerReason   `json:"reason" gorm:"not null"`
+	ReferenceType string         `json:"reference_type,omitempty"` // e.g. plant, garden, plant_type
+	ReferenceID   *uuid.UUID     `json:"reference_id,omitempty" gorm:"type:uuid"`
+	Note          string         `json:"note,omitempty"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"index:idx_ledger_user_created"`
+}
+
+func (e *LedgerEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
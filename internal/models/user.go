@@ -45,8 +45,8 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 // UserAchievement represents user achievements
 type UserAchievement struct {
 	ID            uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID        uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
-	AchievementID uuid.UUID `json:"achievement_id" gorm:"type:uuid;not null"`
+	UserID        uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_achievement"`
+	AchievementID uuid.UUID `json:"achievement_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_achievement"`
 	UnlockedAt    time.Time `json:"unlocked_at"`
 
 	// Relationships
@@ -70,6 +70,10 @@ type Achievement struct {
 	Points      int       `json:"points" gorm:"default:0"`
 	Category    string    `json:"category"`
 
+	// Rule: unlocked once the user's progress counter reaches the goal
+	Counter string `json:"counter" gorm:"index"` // e.g. plants_harvested, see pkg/game/achievements.go
+	Goal    int    `json:"goal" gorm:"default:1"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -80,3 +84,20 @@ func (a *Achievement) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// UserCounter tracks a user's progress towards achievements, such as how many plants they harvested
+type UserCounter struct {
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	Counter   string    `json:"counter" gorm:"primaryKey"`
+	Value     int       `json:"value" gorm:"not null;default:0"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserCounterItem remembers what a distinct counter has already counted, such as the
+// plant types a user has planted
+type UserCounterItem struct {
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	Counter   string    `json:"counter" gorm:"primaryKey"`
+	Item      string    `json:"item" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
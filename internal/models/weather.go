@@ -15,6 +15,7 @@ type Weather struct {
 	Humidity    int              `json:"humidity" gorm:"not null"`        // 0-100
 	WindSpeed   float64          `json:"wind_speed" gorm:"default:0"`     // km/h
 	Pressure    float64          `json:"pressure" gorm:"default:1013.25"` // hPa
+	Event       WeatherEvent     `json:"event,omitempty"`                 // extreme weather, if any
 
 	// Effects on plants
 	GrowthMultiplier     float64 `json:"growth_multiplier" gorm:"default:1.0"`
@@ -45,16 +46,59 @@ const (
 	WeatherSnowy  WeatherCondition = "snowy"
 )
 
-// WeatherForecast represents weather predictions
+// Valid reports whether the condition is one the game knows
+func (c WeatherCondition) Valid() bool {
+	switch c {
+	case WeatherSunny, WeatherCloudy, WeatherRainy, WeatherStormy, WeatherFoggy, WeatherWindy, WeatherSnowy:
+		return true
+	default:
+		return false
+	}
+}
+
+// WeatherEvent is rare extreme weather that damages plants outside a greenhouse
+type WeatherEvent string
+
+const (
+	WeatherEventHail     WeatherEvent = "hail"
+	WeatherEventFrost    WeatherEvent = "frost"
+	WeatherEventHeatwave WeatherEvent = "heatwave"
+	WeatherEventDrought  WeatherEvent = "drought"
+	WeatherEventFlood    WeatherEvent = "flood"
+)
+
+// Valid reports whether the event is one the game knows
+func (e WeatherEvent) Valid() bool {
+	switch e {
+	case WeatherEventHail, WeatherEventFrost, WeatherEventHeatwave, WeatherEventDrought, WeatherEventFlood:
+		return true
+	default:
+		return false
+	}
+}
+
+// WeatherWarning warns of extreme weather on the way
+type WeatherWarning struct {
+	Event      WeatherEvent `json:"event"`
+	StartsAt   time.Time    `json:"starts_at"`
+	EndsAt     time.Time    `json:"ends_at"`
+	Greenhouse float64      `json:"greenhouse_protection"` // share of the damage a greenhouse keeps off
+	Advice     string       `json:"advice"`
+}
+
+// WeatherForecast is weather the engine has drawn ahead of time. It becomes the
+// current weather when its time comes.
 type WeatherForecast struct {
 	ID          uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	Condition   WeatherCondition `json:"condition" gorm:"not null"`
 	Temperature float64          `json:"temperature" gorm:"not null"`
 	Humidity    int              `json:"humidity" gorm:"not null"`
-	Probability int              `json:"probability" gorm:"not null"` // 0-100
+	WindSpeed   float64          `json:"wind_speed" gorm:"default:0"` // km/h
+	Event       WeatherEvent     `json:"event,omitempty"`
+	Probability int              `json:"probability" gorm:"not null"` // 0-100, the model's chance of this condition at that time
 
 	// Timestamps
-	ForecastFor time.Time `json:"forecast_for"`
+	ForecastFor time.Time `json:"forecast_for" gorm:"index"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
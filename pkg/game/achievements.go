@@ -0,0 +1,292 @@
+package game
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/my-garden/api/internal/database"
+	"github.com/my-garden/api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Progress counters achievements can be defined against. An achievement row unlocks
+// once the user's counter named in its Counter column reaches its Goal, so new
+// achievements only need a row in the achievements table.
+const (
+	CounterGardensCreated    = "gardens_created"
+	CounterPlantsPlanted     = "plants_planted"
+	CounterPlantTypesPlanted = "plant_types_planted" // distinct plant types
+	CounterPlantsHarvested   = "plants_harvested"
+	CounterWeatherChecks     = "weather_checks"
+	CounterLevel             = "level" // highest level reached
+)
+
+// PlantTypeHarvestCounter counts harvests of a single plant type, such as
+// "plants_harvested:123e4567-e89b-12d3-a456-426614174000"
+func PlantTypeHarvestCounter(plantTypeID uuid.UUID) string {
+	return CounterPlantsHarvested + ":" + plantTypeID.String()
+}
+
+// counterKind is how an update changes a counter
+type counterKind int
+
+const (
+	counterAdd      counterKind = iota // add Amount
+	counterDistinct                    // add one the first time Item is seen
+	counterMax                         // raise to Amount
+)
+
+// counterUpdate is a change an event makes to one of a user's counters
+type counterUpdate struct {
+	Counter string
+	Kind    counterKind
+	Amount  int
+	Item    string
+}
+
+// AchievementProgress is how far a user is towards an achievement
+type AchievementProgress struct {
+	Achievement models.Achievement `json:"achievement"`
+	Progress    int                `json:"progress"`
+	Goal        int                `json:"goal"`
+	Percent     float64            `json:"percent"`
+	Unlocked    bool               `json:"unlocked"`
+	UnlockedAt  *time.Time         `json:"unlocked_at,omitempty"`
+}
+
+// Achievements tracks player progress and awards achievements as events happen
+type Achievements struct {
+	db      *database.Database
+	publish func(Event)
+}
+
+func NewAchievements(db *database.Database, publish func(Event)) *Achievements {
+	return &Achievements{
+		db:      db,
+		publish: publish,
+	}
+}
+
+// counterUpdates maps a domain event to the progress it represents
+func counterUpdates(event Event) []counterUpdate {
+	switch event.Type {
+	case EventGardenCreated:
+		return []counterUpdate{{Counter: CounterGardensCreated, Kind: counterAdd, Amount: 1}}
+
+	case EventPlantUpdated:
+		var data PlantEventData
+		if err := event.Decode(&data); err != nil || data.Action != UpdatePlantPlanted {
+			return nil
+		}
+		return []counterUpdate{
+			{Counter: CounterPlantsPlanted, Kind: counterAdd, Amount: 1},
+			{Counter: CounterPlantTypesPlanted, Kind: counterDistinct, Item: data.Plant.PlantTypeID.String()},
+		}
+
+	case EventHarvestCompleted:
+		var data HarvestEventData
+		if err := event.Decode(&data); err != nil {
+			log.Printf("Failed to decode harvest event %s: %v", event.ID, err)
+			return nil
+		}
+		return []counterUpdate{
+			{Counter: CounterPlantsHarvested, Kind: counterAdd, Amount: 1},
+			{Counter: PlantTypeHarvestCounter(data.PlantTypeID), Kind: counterAdd, Amount: 1},
+		}
+
+	case EventWeatherChecked:
+		return []counterUpdate{{Counter: CounterWeatherChecks, Kind: counterAdd, Amount: 1}}
+
+	case EventLevelChanged:
+		var data LevelEventData
+		if err := event.Decode(&data); err != nil {
+			log.Printf("Failed to decode level event %s: %v", event.ID, err)
+			return nil
+		}
+		return []counterUpdate{{Counter: CounterLevel, Kind: counterMax, Amount: data.Level}}
+
+	default:
+		return nil
+	}
+}
+
+// HandleEvent updates the progress counters an event affects and awards any
+// achievements they complete
+func (a *Achievements) HandleEvent(ctx context.Context, event Event) {
+	if event.UserID == uuid.Nil {
+		return
+	}
+	updates := counterUpdates(event)
+	if len(updates) == 0 {
+		return
+	}
+
+	var changed []string
+	for _, update := range updates {
+		ok, err := a.apply(event.UserID, update)
+		if err != nil {
+			log.Printf("Failed to update %s progress for user %s: %v", update.Counter, event.UserID, err)
+			continue
+		}
+		if ok {
+			changed = append(changed, update.Counter)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	if err := a.award(event.UserID, changed); err != nil {
+		log.Printf("Failed to award achievements to user %s: %v", event.UserID, err)
+	}
+}
+
+// apply makes a single counter update, reporting whether the counter changed
+func (a *Achievements) apply(userID uuid.UUID, update counterUpdate) (bool, error) {
+	counter := models.UserCounter{UserID: userID, Counter: update.Counter}
+	columns := []clause.Column{{Name: "user_id"}, {Name: "counter"}}
+
+	switch update.Kind {
+	case counterDistinct:
+		// Only the first sighting of an item counts
+		item := models.UserCounterItem{UserID: userID, Counter: update.Counter, Item: update.Item}
+		result := a.db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&item)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return false, result.Error
+		}
+		counter.Value = 1
+		return true, a.db.DB.Clauses(clause.OnConflict{
+			Columns:   columns,
+			DoUpdates: clause.Assignments(map[string]interface{}{"value": gorm.Expr("user_counters.value + 1"), "updated_at": time.Now()}),
+		}).Create(&counter).Error
+
+	case counterMax:
+		counter.Value = update.Amount
+		result := a.db.DB.Clauses(clause.OnConflict{
+			Columns:   columns,
+			DoUpdates: clause.Assignments(map[string]interface{}{"value": gorm.Expr("EXCLUDED.value"), "updated_at": time.Now()}),
+			Where:     clause.Where{Exprs: []clause.Expression{gorm.Expr("user_counters.value < EXCLUDED.value")}},
+		}).Create(&counter)
+		return result.RowsAffected > 0, result.Error
+
+	default:
+		counter.Value = update.Amount
+		return true, a.db.DB.Clauses(clause.OnConflict{
+			Columns:   columns,
+			DoUpdates: clause.Assignments(map[string]interface{}{"value": gorm.Expr("user_counters.value + EXCLUDED.value"), "updated_at": time.Now()}),
+		}).Create(&counter).Error
+	}
+}
+
+// award unlocks every achievement on the given counters whose goal the user has reached.
+// Awards are idempotent, so an achievement is only ever unlocked once per user.
+func (a *Achievements) award(userID uuid.UUID, counters []string) error {
+	var completed []models.Achievement
+	if err := a.db.DB.
+		Joins("JOIN user_counters ON user_counters.counter = achievements.counter AND user_counters.user_id = ?", userID).
+		Where("achievements.counter IN ? AND user_counters.value >= achievements.goal", counters).
+		Where("NOT EXISTS (SELECT 1 FROM user_achievements WHERE user_achievements.user_id = ? AND user_achievements.achievement_id = achievements.id)", userID).
+		Find(&completed).Error; err != nil {
+		return err
+	}
+
+	for _, achievement := range completed {
+		unlocked := models.UserAchievement{
+			UserID:        userID,
+			AchievementID: achievement.ID,
+			UnlockedAt:    time.Now(),
+		}
+		result := a.db.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&unlocked)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		log.Printf("User %s unlocked achievement %s", userID, achievement.Name)
+		a.publish(NewAchievementUnlockedEvent(userID, achievement, unlocked.UnlockedAt))
+	}
+	return nil
+}
+
+// AwardReached awards every achievement users have reached without being given it, such
+// as through progress counted from before it was tracked or a goal that was lowered
+func (a *Achievements) AwardReached() error {
+	var reached []models.UserCounter
+	if err := a.db.DB.
+		Distinct("user_counters.user_id", "user_counters.counter").
+		Joins("JOIN achievements ON achievements.counter = user_counters.counter AND user_counters.value >= achievements.goal").
+		Where("NOT EXISTS (SELECT 1 FROM user_achievements WHERE user_achievements.user_id = user_counters.user_id AND user_achievements.achievement_id = achievements.id)").
+		Find(&reached).Error; err != nil {
+		return err
+	}
+
+	counters := make(map[uuid.UUID][]string)
+	for _, counter := range reached {
+		counters[counter.UserID] = append(counters[counter.UserID], counter.Counter)
+	}
+	for userID, names := range counters {
+		if err := a.award(userID, names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Progress reports how far a user is towards every achievement
+func (a *Achievements) Progress(userID uuid.UUID) ([]AchievementProgress, error) {
+	var achievements []models.Achievement
+	if err := a.db.DB.Order("category, goal, name").Find(&achievements).Error; err != nil {
+		return nil, err
+	}
+
+	var counters []models.UserCounter
+	if err := a.db.DB.Where("user_id = ?", userID).Find(&counters).Error; err != nil {
+		return nil, err
+	}
+	values := make(map[string]int, len(counters))
+	for _, counter := range counters {
+		values[counter.Counter] = counter.Value
+	}
+
+	var unlocked []models.UserAchievement
+	if err := a.db.DB.Where("user_id = ?", userID).Find(&unlocked).Error; err != nil {
+		return nil, err
+	}
+	unlockedAt := make(map[uuid.UUID]time.Time, len(unlocked))
+	for _, ua := range unlocked {
+		unlockedAt[ua.AchievementID] = ua.UnlockedAt
+	}
+
+	progress := make([]AchievementProgress, 0, len(achievements))
+	for _, achievement := range achievements {
+		goal := achievement.Goal
+		if goal < 1 {
+			goal = 1
+		}
+
+		entry := AchievementProgress{
+			Achievement: achievement,
+			Progress:    values[achievement.Counter],
+			Goal:        goal,
+		}
+		if at, ok := unlockedAt[achievement.ID]; ok {
+			entry.Unlocked = true
+			entry.UnlockedAt = &at
+			entry.Progress = goal
+		}
+		if entry.Progress > goal {
+			entry.Progress = goal
+		}
+		entry.Percent = math.Round(float64(entry.Progress)/float64(goal)*1000) / 10
+
+		progress = append(progress, entry)
+	}
+
+	return progress, nil
+}
@@ -0,0 +1,164 @@
+package game
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/my-garden/api/internal/models"
+)
+
+// UpdateType identifies the kind of change pushed to garden subscribers
+type UpdateType string
+
+const (
+	UpdateGardenSnapshot  UpdateType = "garden_snapshot"
+	UpdatePlantGrowth     UpdateType = "plant_growth"
+	UpdateHarvestReady    UpdateType = "harvest_ready"
+	UpdatePlantWithered   UpdateType = "plant_withered"
+	UpdatePlantPlanted    UpdateType = "plant_planted"
+	UpdatePlantWatered    UpdateType = "plant_watered"
+	UpdatePlantFertilized UpdateType = "plant_fertilized"
+	UpdatePlantHarvested  UpdateType = "plant_harvested"
+	UpdatePlantRemoved    UpdateType = "plant_removed"
+	UpdatePlantComposted  UpdateType = "plant_composted"
+	UpdatePlantMoved      UpdateType = "plant_moved"
+	UpdatePlantRevived    UpdateType = "plant_revived"
+	UpdateWeatherChange   UpdateType = "weather_change"
+)
+
+// subscriberBufferSize bounds how many updates a slow client can lag behind
+const subscriberBufferSize = 32
+
+// GardenUpdate is a single message delivered to garden subscribers
+type GardenUpdate struct {
+	Type UpdateType  `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// PlantDelta carries the mutable state of a plant after a change
+type PlantDelta struct {
+	PlantID         uuid.UUID              `json:"plant_id"`
+	GardenID        uuid.UUID              `json:"garden_id"`
+	PlantTypeID     uuid.UUID              `json:"plant_type_id"`
+	Position        int                    `json:"position"`
+	Stage           models.PlantStage      `json:"stage"`
+	Health          int                    `json:"health"`
+	WaterLevel      int                    `json:"water_level"`
+	FertilizerLevel int                    `json:"fertilizer_level"`
+	GrowthProgress  float64                `json:"growth_progress"`
+	Condition       *models.PlantCondition `json:"condition,omitempty"`
+	Ripeness        *models.PlantRipeness  `json:"ripeness,omitempty"`
+	DeathReason     models.DeathReason     `json:"death_reason,omitempty"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+// NewPlantDelta builds a delta from the current state of a plant
+func NewPlantDelta(plant *models.Plant) PlantDelta {
+	return PlantDelta{
+		PlantID:         plant.ID,
+		GardenID:        plant.GardenID,
+		PlantTypeID:     plant.PlantTypeID,
+		Position:        plant.Position,
+		Stage:           plant.Stage,
+		Health:          plant.Health,
+		WaterLevel:      plant.WaterLevel,
+		FertilizerLevel: plant.FertilizerLevel,
+		GrowthProgress:  plant.GrowthProgress,
+		Condition:       plant.Condition,
+		Ripeness:        plant.Ripeness,
+		DeathReason:     plant.DeathReason,
+		UpdatedAt:       time.Now(),
+	}
+}
+
+// Subscription receives updates for a single garden
+type Subscription struct {
+	GardenID uuid.UUID
+	updates  chan GardenUpdate
+}
+
+// Updates returns the channel updates are delivered on. It is closed on unsubscribe.
+func (s *Subscription) Updates() <-chan GardenUpdate {
+	return s.updates
+}
+
+// Broadcaster fans garden updates out to in-process subscribers
+type Broadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[*Subscription]struct{}
+}
+
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{
+		subscribers: make(map[uuid.UUID]map[*Subscription]struct{}),
+	}
+}
+
+func (b *Broadcaster) Subscribe(gardenID uuid.UUID) *Subscription {
+	sub := &Subscription{
+		GardenID: gardenID,
+		updates:  make(chan GardenUpdate, subscriberBufferSize),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers[gardenID] == nil {
+		b.subscribers[gardenID] = make(map[*Subscription]struct{})
+	}
+	b.subscribers[gardenID][sub] = struct{}{}
+
+	return sub
+}
+
+func (b *Broadcaster) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs, ok := b.subscribers[sub.GardenID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subscribers, sub.GardenID)
+	}
+	close(sub.updates)
+}
+
+// Publish delivers an update to every subscriber of a garden
+func (b *Broadcaster) Publish(gardenID uuid.UUID, update GardenUpdate) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers[gardenID] {
+		b.deliver(sub, update)
+	}
+}
+
+// PublishAll delivers an update to every subscriber regardless of garden
+func (b *Broadcaster) PublishAll(update GardenUpdate) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, subs := range b.subscribers {
+		for sub := range subs {
+			b.deliver(sub, update)
+		}
+	}
+}
+
+func (b *Broadcaster) deliver(sub *Subscription, update GardenUpdate) {
+	// Never block the publisher on a slow client
+	select {
+	case sub.updates <- update:
+	default:
+		log.Printf("Dropping %s update for garden %s: subscriber is too slow", update.Type, sub.GardenID)
+	}
+}
@@ -2,10 +2,13 @@ package game
 
 import (
 	"context"
+	"fmt"
 	"log"
-	"math/rand"
+	"os"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/my-garden/api/internal/config"
 	"github.com/my-garden/api/internal/database"
 	"github.com/my-garden/api/internal/models"
@@ -13,164 +16,186 @@ import (
 )
 
 type GameEngine struct {
-	db            *database.Database
-	redis         *redis.Client
-	config        *config.Config
-	ctx           context.Context
-	cancel        context.CancelFunc
-	tickTicker    *time.Ticker
-	weatherTicker *time.Ticker
+	db           *database.Database
+	redis        *redis.Client
+	config       *config.Config
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	broadcaster  *Broadcaster
+	events       *EventBus
+	elector      *LeaderElector
+	instanceID   string
+	leaderboard  *Leaderboard
+	achievements *Achievements
+	ledger       *Ledger
+
+	listenersMu sync.RWMutex
+	listeners   []EventListener
+
+	growthModelsMu sync.RWMutex
+	growthModels   map[string]GrowthModel
+
+	// weatherModel is the game's own weather, used to forecast and when the provider fails
+	weatherModel    *MarkovWeather
+	weatherProvider WeatherProvider
 }
 
 func NewGameEngine(db *database.Database, redis *redis.Client, cfg *config.Config) *GameEngine {
 	ctx, cancel := context.WithCancel(context.Background())
+	instanceID := newInstanceID()
+
+	engine := &GameEngine{
+		db:          db,
+		redis:       redis,
+		config:      cfg,
+		ctx:         ctx,
+		cancel:      cancel,
+		broadcaster: NewBroadcaster(),
+		events:      NewEventBus(redis, instanceID),
+		elector:     NewLeaderElector(redis, instanceID, cfg.Game.LeaderLeaseTTL),
+		instanceID:  instanceID,
+		leaderboard: NewLeaderboard(db, redis),
+		ledger:      NewLedger(db),
+
+		growthModels: builtinGrowthModels(cfg.Game.TickInterval),
+	}
+	engine.weatherModel = NewMarkovWeather(cfg.Game.WeatherTransitions)
+	engine.weatherProvider = engine.weatherModel
+	engine.achievements = NewAchievements(db, engine.PublishEvent)
+	engine.OnEvent(engine.leaderboard.HandleEvent)
+	engine.OnEvent(engine.achievements.HandleEvent)
+
+	return engine
+}
 
-	return &GameEngine{
-		db:            db,
-		redis:         redis,
-		config:        cfg,
-		ctx:           ctx,
-		cancel:        cancel,
-		tickTicker:    time.NewTicker(cfg.Game.TickInterval),
-		weatherTicker: time.NewTicker(cfg.Game.WeatherUpdateInterval),
+// newInstanceID identifies this replica in published events and leader election
+func newInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
 	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
 }
 
 func (g *GameEngine) Start() {
 	log.Println("Starting game engine...")
 
-	// Start game tick loop
-	go g.gameTickLoop()
+	// Only the elected leader runs the simulation loops
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		g.elector.Run(g.ctx, g.lead)
+	}()
 
-	// Start weather update loop
-	go g.weatherUpdateLoop()
-
-	// Initialize current weather
-	g.updateWeather()
+	// Relay events from every replica to local subscribers
+	go g.relayEvents()
 }
 
 func (g *GameEngine) Stop() {
 	log.Println("Stopping game engine...")
 	g.cancel()
-	g.tickTicker.Stop()
-	g.weatherTicker.Stop()
-}
 
-func (g *GameEngine) gameTickLoop() {
-	for {
-		select {
-		case <-g.ctx.Done():
-			return
-		case <-g.tickTicker.C:
-			g.processGameTick()
-		}
-	}
+	// Wait for the simulation loops to stop and the leader lease to be released, so
+	// another replica can take over immediately without the two overlapping
+	g.wg.Wait()
 }
 
-func (g *GameEngine) weatherUpdateLoop() {
-	for {
-		select {
-		case <-g.ctx.Done():
-			return
-		case <-g.weatherTicker.C:
-			g.updateWeather()
-		}
+// lead runs the simulation loops for as long as this instance holds the leader lease,
+// and returns once they have all stopped so no simulation outlives the lease
+func (g *GameEngine) lead(ctx context.Context) {
+	// Make sure there is current weather before the first tick
+	if weather, err := g.GetCurrentWeather(); err != nil || time.Now().After(weather.ValidUntil) {
+		g.updateWeather()
 	}
-}
-
-func (g *GameEngine) processGameTick() {
-	log.Println("Processing game tick...")
 
-	// Get current weather
-	var currentWeather models.Weather
-	if err := g.db.DB.Order("created_at DESC").First(&currentWeather).Error; err != nil {
-		log.Printf("Failed to get current weather: %v", err)
-		return
+	g.leaderboard.RebuildIfMissing(ctx)
+	if err := g.achievements.AwardReached(); err != nil {
+		log.Printf("Failed to award reached achievements: %v", err)
 	}
 
-	// Process all plants
-	var plants []models.Plant
-	if err := g.db.DB.Preload("PlantType").Preload("Garden").Find(&plants).Error; err != nil {
-		log.Printf("Failed to fetch plants: %v", err)
-		return
+	var loops sync.WaitGroup
+	defer loops.Wait()
+	for _, loop := range []func(context.Context){g.leaderboard.RunResets, g.ledger.RunReconciliation, g.weatherUpdateLoop} {
+		loops.Add(1)
+		go func(loop func(context.Context)) {
+			defer loops.Done()
+			loop(ctx)
+		}(loop)
 	}
 
-	for _, plant := range plants {
-		g.processPlantGrowth(&plant, &currentWeather)
-	}
+	g.gameTickLoop(ctx)
 }
 
-func (g *GameEngine) processPlantGrowth(plant *models.Plant, weather *models.Weather) {
-	// Skip if plant is already harvested or withered
-	if plant.Stage == models.PlantStageHarvestable || plant.Stage == models.PlantStageWithered {
-		return
-	}
+// gameTickLoop periodically sweeps plants that haven't been evaluated on read
+func (g *GameEngine) gameTickLoop(ctx context.Context) {
+	interval := g.config.Game.PlantGrowthInterval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	// Calculate growth progress
-	baseGrowthRate := 1.0 / float64(plant.PlantType.GrowthTime) // Growth per minute
-	weatherMultiplier := weather.GrowthMultiplier
+	// Let clients count down to the first tick under this leader
+	stats := g.loadTickStats(ctx)
+	stats.LeaderID = g.instanceID
+	stats.NextTickAt = time.Now().Add(interval)
+	g.saveTickStats(ctx, stats)
 
-	// Apply water and fertilizer bonuses
-	waterBonus := 1.0
-	if plant.WaterLevel > 70 {
-		waterBonus = 1.2
-	} else if plant.WaterLevel < 30 {
-		waterBonus = 0.8
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.runTick(ctx, stats)
+		}
 	}
+}
 
-	// Calculate total growth for this tick
-	tickDuration := g.config.Game.TickInterval.Minutes()
-	growthIncrement := baseGrowthRate * weatherMultiplier * waterBonus * tickDuration
-
-	plant.GrowthProgress += growthIncrement
-
-	// Update plant stage based on growth progress
-	g.updatePlantStage(plant)
+// runTick runs one round of background simulation and records how it went
+func (g *GameEngine) runTick(ctx context.Context, stats *TickStats) {
+	started := time.Now()
 
-	// Reduce water level due to evaporation
-	evaporationRate := weather.WaterEvaporationRate * tickDuration / 60.0 // per minute
-	plant.WaterLevel = max(0, plant.WaterLevel-int(evaporationRate*10))
+	processed, sweepFailures := g.sweepPlants()
+	composted, compostFailures := g.compostWithered()
 
-	// Update plant health based on water level
-	if plant.WaterLevel < 20 {
-		plant.Health = max(0, plant.Health-5)
-	} else if plant.WaterLevel > 80 {
-		plant.Health = min(100, plant.Health+2)
-	}
+	stats.record(started, time.Since(started), processed, composted, sweepFailures+compostFailures)
+	stats.NextTickAt = started.Add(g.config.Game.PlantGrowthInterval)
+	g.saveTickStats(ctx, stats)
+}
 
-	// Check if plant has withered
-	if plant.Health <= 0 {
-		plant.Stage = models.PlantStageWithered
-	}
+func (g *GameEngine) weatherUpdateLoop(ctx context.Context) {
+	ticker := time.NewTicker(g.config.Game.WeatherUpdateInterval)
+	defer ticker.Stop()
 
-	// Save plant changes
-	if err := g.db.DB.Save(plant).Error; err != nil {
-		log.Printf("Failed to save plant %s: %v", plant.ID, err)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.updateWeather()
+		}
 	}
 }
 
-func (g *GameEngine) updatePlantStage(plant *models.Plant) {
-	progress := plant.GrowthProgress
-
+// growthEvent builds the domain event describing what the simulation changed
+func growthEvent(userID uuid.UUID, before, after *models.Plant) (Event, bool) {
 	switch {
-	case progress < 20:
-		plant.Stage = models.PlantStageSeed
-	case progress < 40:
-		plant.Stage = models.PlantStageSprout
-	case progress < 70:
-		plant.Stage = models.PlantStageGrowing
-	case progress < 100:
-		plant.Stage = models.PlantStageMature
+	case after.Stage != before.Stage && after.Stage == models.PlantStageWithered:
+		return NewPlantWitheredEvent(userID, after, before.Stage), true
+	case after.Stage != before.Stage:
+		return NewPlantStageChangedEvent(userID, after, before.Stage), true
+	case after.GrowthProgress != before.GrowthProgress,
+		after.WaterLevel != before.WaterLevel,
+		after.Health != before.Health:
+		return NewPlantUpdatedEvent(userID, UpdatePlantGrowth, after), true
 	default:
-		plant.Stage = models.PlantStageHarvestable
+		return Event{}, false
 	}
 }
 
 func (g *GameEngine) updateWeather() {
 	log.Println("Updating weather...")
 
-	// Generate new weather conditions
-	weather := g.generateWeather()
+	// Take the forecast weather that has come due, or draw some if none has
+	weather := g.dueWeather(time.Now())
 
 	// Save to database
 	if err := g.db.DB.Create(&weather).Error; err != nil {
@@ -181,142 +206,141 @@ func (g *GameEngine) updateWeather() {
 	// Cache current weather in Redis
 	g.cacheCurrentWeather(weather)
 
+	// Let every connected garden know the weather changed
+	g.PublishEvent(NewWeatherChangedEvent(weather))
+
 	log.Printf("Weather updated: %s, Temperature: %.1f°C, Growth Multiplier: %.2f",
 		weather.Condition, weather.Temperature, weather.GrowthMultiplier)
+
+	// Keep the forecast reaching the full horizon ahead of the new weather
+	if err := g.extendForecast(weather); err != nil {
+		log.Printf("Failed to extend weather forecast: %v", err)
+	}
 }
 
-func (g *GameEngine) generateWeather() models.Weather {
-	// Get current season
-	season := models.GetSeason(time.Now())
+func (g *GameEngine) cacheCurrentWeather(weather models.Weather) {
+	// Cache weather in Redis for quick access
+	key := "weather:current"
+	// Note: In a real implementation, you'd serialize the weather struct to JSON
+	// For now, we'll just store a simple string
+	g.redis.Set(g.ctx, key, weather.Condition, g.config.Game.WeatherUpdateInterval)
+}
 
-	// Define weather probabilities based on season
-	weatherConditions := g.getWeatherConditionsForSeason(season)
+func (g *GameEngine) GetCurrentWeather() (*models.Weather, error) {
+	var weather models.Weather
+	err := g.db.DB.Order("created_at DESC").First(&weather).Error
+	if err != nil {
+		return nil, err
+	}
+	return &weather, nil
+}
 
-	// Select random weather condition
-	selectedCondition := weatherConditions[rand.Intn(len(weatherConditions))]
+// Subscribe registers for real-time updates about a garden
+func (g *GameEngine) Subscribe(gardenID uuid.UUID) *Subscription {
+	return g.broadcaster.Subscribe(gardenID)
+}
 
-	// Generate temperature based on season and weather
-	temperature := g.generateTemperature(season, selectedCondition)
+// Unsubscribe stops delivery to a subscription and closes its channel
+func (g *GameEngine) Unsubscribe(sub *Subscription) {
+	g.broadcaster.Unsubscribe(sub)
+}
 
-	// Generate humidity
-	humidity := rand.Intn(40) + 30 // 30-70%
+// InstanceID identifies this replica
+func (g *GameEngine) InstanceID() string {
+	return g.instanceID
+}
 
-	// Generate wind speed
-	windSpeed := rand.Float64() * 20 // 0-20 km/h
+// Leader reports which replica currently runs the simulation loops
+func (g *GameEngine) Leader() (LeaderInfo, error) {
+	return g.elector.Info(g.ctx)
+}
 
-	// Get weather effects
-	growthMultiplier, waterEvaporationRate := models.GetWeatherEffects(selectedCondition)
+// Events exposes the event bus so handlers can subscribe to domain events
+func (g *GameEngine) Events() *EventBus {
+	return g.events
+}
 
-	weather := models.Weather{
-		Condition:            selectedCondition,
-		Temperature:          temperature,
-		Humidity:             humidity,
-		WindSpeed:            windSpeed,
-		Pressure:             1013.25, // Standard atmospheric pressure
-		GrowthMultiplier:     growthMultiplier,
-		WaterEvaporationRate: waterEvaporationRate,
-		ValidUntil:           time.Now().Add(g.config.Game.WeatherUpdateInterval),
-	}
+// Leaderboard exposes the player rankings
+func (g *GameEngine) Leaderboard() *Leaderboard {
+	return g.leaderboard
+}
 
-	return weather
+// Ledger exposes the coin and experience ledger
+func (g *GameEngine) Ledger() *Ledger {
+	return g.ledger
 }
 
-func (g *GameEngine) getWeatherConditionsForSeason(season models.Season) []models.WeatherCondition {
-	switch season {
-	case models.SeasonSpring:
-		return []models.WeatherCondition{
-			models.WeatherSunny, models.WeatherCloudy, models.WeatherRainy,
-			models.WeatherFoggy, models.WeatherWindy,
-		}
-	case models.SeasonSummer:
-		return []models.WeatherCondition{
-			models.WeatherSunny, models.WeatherCloudy, models.WeatherStormy,
-			models.WeatherWindy,
-		}
-	case models.SeasonAutumn:
-		return []models.WeatherCondition{
-			models.WeatherCloudy, models.WeatherRainy, models.WeatherFoggy,
-			models.WeatherWindy, models.WeatherSunny,
-		}
-	case models.SeasonWinter:
-		return []models.WeatherCondition{
-			models.WeatherCloudy, models.WeatherSnowy, models.WeatherFoggy,
-			models.WeatherWindy,
-		}
-	default:
-		return []models.WeatherCondition{
-			models.WeatherSunny, models.WeatherCloudy, models.WeatherRainy,
-		}
-	}
+// Achievements exposes player achievement progress
+func (g *GameEngine) Achievements() *Achievements {
+	return g.achievements
 }
 
-func (g *GameEngine) generateTemperature(season models.Season, condition models.WeatherCondition) float64 {
-	baseTemp := g.getBaseTemperatureForSeason(season)
-
-	// Adjust temperature based on weather condition
-	switch condition {
-	case models.WeatherSunny:
-		baseTemp += rand.Float64()*5 + 2 // +2 to +7°C
-	case models.WeatherCloudy:
-		baseTemp += rand.Float64()*3 - 1 // -1 to +2°C
-	case models.WeatherRainy:
-		baseTemp += rand.Float64()*2 - 2 // -2 to 0°C
-	case models.WeatherStormy:
-		baseTemp += rand.Float64()*3 - 3 // -3 to 0°C
-	case models.WeatherFoggy:
-		baseTemp += rand.Float64()*2 - 1 // -1 to +1°C
-	case models.WeatherWindy:
-		baseTemp += rand.Float64()*2 - 1 // -1 to +1°C
-	case models.WeatherSnowy:
-		baseTemp += rand.Float64()*3 - 5 // -5 to -2°C
-	}
+// EventListener reacts to a domain event on the instance that published it
+type EventListener func(ctx context.Context, event Event)
 
-	return baseTemp
+// OnEvent registers a listener that runs synchronously for every event published by
+// this instance. Because each event is published exactly once, listeners see every
+// event once across all replicas, unlike subscribers to the event bus.
+func (g *GameEngine) OnEvent(listener EventListener) {
+	g.listenersMu.Lock()
+	defer g.listenersMu.Unlock()
+	g.listeners = append(g.listeners, listener)
 }
 
-func (g *GameEngine) getBaseTemperatureForSeason(season models.Season) float64 {
-	switch season {
-	case models.SeasonSpring:
-		return 15.0
-	case models.SeasonSummer:
-		return 25.0
-	case models.SeasonAutumn:
-		return 15.0
-	case models.SeasonWinter:
-		return 5.0
-	default:
-		return 15.0
+// PublishEvent runs local listeners and fans a domain event out to every replica. If
+// Redis is unavailable the event is still delivered to subscribers on this instance.
+func (g *GameEngine) PublishEvent(event Event) {
+	g.listenersMu.RLock()
+	listeners := g.listeners
+	g.listenersMu.RUnlock()
+	for _, listener := range listeners {
+		listener(g.ctx, event)
 	}
-}
-
-func (g *GameEngine) cacheCurrentWeather(weather models.Weather) {
-	// Cache weather in Redis for quick access
-	key := "weather:current"
-	// Note: In a real implementation, you'd serialize the weather struct to JSON
-	// For now, we'll just store a simple string
-	g.redis.Set(g.ctx, key, weather.Condition, g.config.Game.WeatherUpdateInterval)
-}
 
-func (g *GameEngine) GetCurrentWeather() (*models.Weather, error) {
-	var weather models.Weather
-	err := g.db.DB.Order("created_at DESC").First(&weather).Error
-	if err != nil {
-		return nil, err
+	if err := g.events.Publish(g.ctx, event); err != nil {
+		log.Printf("Failed to publish event, delivering locally only: %v", err)
+		g.deliverLocally(event)
 	}
-	return &weather, nil
 }
 
-// Helper functions
-func max(a, b int) int {
-	if a > b {
-		return a
+// relayEvents forwards garden and weather events from Redis to local WebSocket subscribers
+func (g *GameEngine) relayEvents() {
+	sub := g.events.Subscribe(g.ctx, AllGardensPattern, WeatherChannel)
+	go func() {
+		<-g.ctx.Done()
+		sub.Close()
+	}()
+
+	for event := range sub.Events() {
+		g.deliverLocally(event)
 	}
-	return b
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// deliverLocally converts a domain event into a garden update for local subscribers
+func (g *GameEngine) deliverLocally(event Event) {
+	switch event.Type {
+	case EventWeatherChanged:
+		var weather models.Weather
+		if err := event.Decode(&weather); err != nil {
+			log.Printf("Failed to decode weather event %s: %v", event.ID, err)
+			return
+		}
+		g.broadcaster.PublishAll(GardenUpdate{Type: UpdateWeatherChange, Data: weather})
+
+	case EventPlantStageChanged, EventPlantWithered, EventPlantUpdated:
+		var data PlantEventData
+		if err := event.Decode(&data); err != nil {
+			log.Printf("Failed to decode plant event %s: %v", event.ID, err)
+			return
+		}
+		g.broadcaster.Publish(event.GardenID, GardenUpdate{Type: data.Action, Data: data.Plant})
+
+	case EventHarvestCompleted:
+		var data HarvestEventData
+		if err := event.Decode(&data); err != nil {
+			log.Printf("Failed to decode harvest event %s: %v", event.ID, err)
+			return
+		}
+		g.broadcaster.Publish(event.GardenID, GardenUpdate{Type: UpdatePlantHarvested, Data: data.Plant})
 	}
-	return b
 }
@@ -0,0 +1,322 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/my-garden/api/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// EventType identifies a domain event emitted by the game
+type EventType string
+
+const (
+	EventPlantStageChanged   EventType = "plant_stage_changed"
+	EventPlantWithered       EventType = "plant_withered"
+	EventPlantUpdated        EventType = "plant_updated"
+	EventWeatherChanged      EventType = "weather_changed"
+	EventHarvestCompleted    EventType = "harvest_completed"
+	EventGardenCreated       EventType = "garden_created"
+	EventWeatherChecked      EventType = "weather_checked"
+	EventLevelChanged        EventType = "level_changed"
+	EventAchievementUnlocked EventType = "achievement_unlocked"
+	EventItemsSold           EventType = "items_sold"
+	EventItemsConsumed       EventType = "items_consumed"
+)
+
+// Redis channel layout for domain events
+const (
+	eventChannelPrefix = "game:events:"
+	gardenChannelBase  = eventChannelPrefix + "garden:"
+	userChannelBase    = eventChannelPrefix + "user:"
+
+	// WeatherChannel carries events that concern every player
+	WeatherChannel = eventChannelPrefix + "weather"
+
+	// AllGardensPattern matches the channels of every garden
+	AllGardensPattern = gardenChannelBase + "*"
+)
+
+// GardenChannel returns the Redis channel for events about a garden
+func GardenChannel(gardenID uuid.UUID) string {
+	return gardenChannelBase + gardenID.String()
+}
+
+// UserChannel returns the Redis channel for events about a user
+func UserChannel(userID uuid.UUID) string {
+	return userChannelBase + userID.String()
+}
+
+// Event is the envelope published on Redis for every domain event
+type Event struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       EventType       `json:"type"`
+	GardenID   uuid.UUID       `json:"garden_id,omitempty"`
+	UserID     uuid.UUID       `json:"user_id,omitempty"`
+	Data       json.RawMessage `json:"data"`
+	Source     string          `json:"source"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// Decode unmarshals the event payload into v
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// PlantEventData is the payload of plant stage, withered and update events
+type PlantEventData struct {
+	Action        UpdateType        `json:"action"`
+	PreviousStage models.PlantStage `json:"previous_stage,omitempty"`
+	Plant         PlantDelta        `json:"plant"`
+}
+
+// HarvestEventData is the payload of harvest completed events
+type HarvestEventData struct {
+	Plant            PlantDelta            `json:"plant"`
+	PlantTypeID      uuid.UUID             `json:"plant_type_id"`
+	ItemsHarvested   int                   `json:"items_harvested"`
+	Quality          models.HarvestQuality `json:"quality"`
+	ExperienceEarned int                   `json:"experience_earned"`
+	NewLevel         int                   `json:"new_level"`
+	LevelUp          bool                  `json:"level_up"`
+	Regrowing        bool                  `json:"regrowing"` // perennials grow back instead of withering
+}
+
+// InventoryEventData is the payload of items sold and items consumed events
+type InventoryEventData struct {
+	PlantTypeID      uuid.UUID             `json:"plant_type_id"`
+	Quality          models.HarvestQuality `json:"quality"`
+	Quantity         int                   `json:"quantity"`
+	Remaining        int                   `json:"remaining"`
+	CoinsEarned      int                   `json:"coins_earned,omitempty"`
+	ExperienceEarned int                   `json:"experience_earned,omitempty"`
+}
+
+// WeatherCheckedEventData is the payload of weather checked events
+type WeatherCheckedEventData struct {
+	Lookup string `json:"lookup"` // current, forecast or history
+}
+
+// LevelEventData is the payload of level changed events
+type LevelEventData struct {
+	PreviousLevel int `json:"previous_level"`
+	Level         int `json:"level"`
+}
+
+// AchievementEventData is the payload of achievement unlocked events
+type AchievementEventData struct {
+	Achievement models.Achievement `json:"achievement"`
+	UnlockedAt  time.Time          `json:"unlocked_at"`
+}
+
+func newEvent(eventType EventType, gardenID, userID uuid.UUID, data interface{}) Event {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		// Payloads are plain structs, so this only happens on programmer error
+		log.Printf("Failed to encode %s event: %v", eventType, err)
+	}
+
+	return Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		GardenID:   gardenID,
+		UserID:     userID,
+		Data:       payload,
+		OccurredAt: time.Now(),
+	}
+}
+
+// NewPlantStageChangedEvent reports a plant moving to a new growth stage
+func NewPlantStageChangedEvent(userID uuid.UUID, plant *models.Plant, previous models.PlantStage) Event {
+	action := UpdatePlantGrowth
+	if plant.Stage == models.PlantStageHarvestable {
+		action = UpdateHarvestReady
+	}
+
+	return newEvent(EventPlantStageChanged, plant.GardenID, userID, PlantEventData{
+		Action:        action,
+		PreviousStage: previous,
+		Plant:         NewPlantDelta(plant),
+	})
+}
+
+// NewPlantWitheredEvent reports a plant dying
+func NewPlantWitheredEvent(userID uuid.UUID, plant *models.Plant, previous models.PlantStage) Event {
+	return newEvent(EventPlantWithered, plant.GardenID, userID, PlantEventData{
+		Action:        UpdatePlantWithered,
+		PreviousStage: previous,
+		Plant:         NewPlantDelta(plant),
+	})
+}
+
+// NewPlantUpdatedEvent reports any other change to a plant, such as growth or a player action
+func NewPlantUpdatedEvent(userID uuid.UUID, action UpdateType, plant *models.Plant) Event {
+	return newEvent(EventPlantUpdated, plant.GardenID, userID, PlantEventData{
+		Action: action,
+		Plant:  NewPlantDelta(plant),
+	})
+}
+
+// NewWeatherChangedEvent reports new weather conditions
+func NewWeatherChangedEvent(weather models.Weather) Event {
+	return newEvent(EventWeatherChanged, uuid.Nil, uuid.Nil, weather)
+}
+
+// NewHarvestCompletedEvent reports a successful harvest and its rewards
+func NewHarvestCompletedEvent(userID uuid.UUID, plant *models.Plant, data HarvestEventData) Event {
+	data.Plant = NewPlantDelta(plant)
+	data.PlantTypeID = plant.PlantTypeID
+	return newEvent(EventHarvestCompleted, plant.GardenID, userID, data)
+}
+
+// NewGardenCreatedEvent reports a player creating a garden
+func NewGardenCreatedEvent(garden *models.Garden) Event {
+	return newEvent(EventGardenCreated, garden.ID, garden.UserID, garden)
+}
+
+// NewWeatherCheckedEvent reports a player looking up the weather
+func NewWeatherCheckedEvent(userID uuid.UUID, lookup string) Event {
+	return newEvent(EventWeatherChecked, uuid.Nil, userID, WeatherCheckedEventData{Lookup: lookup})
+}
+
+// NewLevelChangedEvent reports a player reaching a new level
+func NewLevelChangedEvent(userID uuid.UUID, previous, level int) Event {
+	return newEvent(EventLevelChanged, uuid.Nil, userID, LevelEventData{
+		PreviousLevel: previous,
+		Level:         level,
+	})
+}
+
+// NewAchievementUnlockedEvent reports a player earning an achievement
+func NewAchievementUnlockedEvent(userID uuid.UUID, achievement models.Achievement, unlockedAt time.Time) Event {
+	return newEvent(EventAchievementUnlocked, uuid.Nil, userID, AchievementEventData{
+		Achievement: achievement,
+		UnlockedAt:  unlockedAt,
+	})
+}
+
+// NewItemsSoldEvent reports a player selling goods from their inventory
+func NewItemsSoldEvent(userID uuid.UUID, data InventoryEventData) Event {
+	return newEvent(EventItemsSold, uuid.Nil, userID, data)
+}
+
+// NewItemsConsumedEvent reports a player consuming goods from their inventory
+func NewItemsConsumedEvent(userID uuid.UUID, data InventoryEventData) Event {
+	return newEvent(EventItemsConsumed, uuid.Nil, userID, data)
+}
+
+// EventBus publishes domain events to Redis so every API replica sees them
+type EventBus struct {
+	redis  *redis.Client
+	source string
+}
+
+func NewEventBus(redis *redis.Client, source string) *EventBus {
+	return &EventBus{
+		redis:  redis,
+		source: source,
+	}
+}
+
+// Channels returns the Redis channels an event is published on
+func (b *EventBus) Channels(event Event) []string {
+	if event.Type == EventWeatherChanged {
+		return []string{WeatherChannel}
+	}
+
+	var channels []string
+	if event.GardenID != uuid.Nil {
+		channels = append(channels, GardenChannel(event.GardenID))
+	}
+	if event.UserID != uuid.Nil {
+		channels = append(channels, UserChannel(event.UserID))
+	}
+	return channels
+}
+
+// Publish sends an event to all of its channels in a single round trip
+func (b *EventBus) Publish(ctx context.Context, event Event) error {
+	event.Source = b.source
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	channels := b.Channels(event)
+	if len(channels) == 0 {
+		return nil
+	}
+
+	pipe := b.redis.Pipeline()
+	for _, channel := range channels {
+		pipe.Publish(ctx, channel, payload)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", event.Type, err)
+	}
+	return nil
+}
+
+// Subscribe listens on the given channels. Patterns such as "game:events:garden:*"
+// are accepted as well.
+func (b *EventBus) Subscribe(ctx context.Context, channels ...string) *EventSubscription {
+	var exact, patterns []string
+	for _, channel := range channels {
+		if strings.ContainsAny(channel, "*?[") {
+			patterns = append(patterns, channel)
+		} else {
+			exact = append(exact, channel)
+		}
+	}
+
+	pubsub := b.redis.Subscribe(ctx, exact...)
+	if len(patterns) > 0 {
+		if err := pubsub.PSubscribe(ctx, patterns...); err != nil {
+			log.Printf("Failed to subscribe to event patterns %v: %v", patterns, err)
+		}
+	}
+
+	sub := &EventSubscription{
+		pubsub: pubsub,
+		events: make(chan Event, subscriberBufferSize),
+	}
+	go sub.run()
+
+	return sub
+}
+
+// EventSubscription delivers decoded events from Redis
+type EventSubscription struct {
+	pubsub *redis.PubSub
+	events chan Event
+}
+
+// Events returns the channel events are delivered on. Consumers must keep reading
+// until it is closed, which happens after Close.
+func (s *EventSubscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *EventSubscription) Close() error {
+	return s.pubsub.Close()
+}
+
+func (s *EventSubscription) run() {
+	defer close(s.events)
+
+	for msg := range s.pubsub.Channel() {
+		var event Event
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			log.Printf("Discarding malformed event on %s: %v", msg.Channel, err)
+			continue
+		}
+		s.events <- event
+	}
+}
@@ -0,0 +1,196 @@
+package game
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/my-garden/api/internal/models"
+	"gorm.io/gorm"
+)
+
+// The forecast is the weather the engine has already drawn for the coming updates.
+// Every weather update takes the entry that has come due as the new weather and draws
+// more on the end so the forecast always reaches ForecastHorizon ahead. Each entry's
+// probability is the model's chance of that condition at that time given the weather
+// now, so near entries are given with more confidence than far ones. Providers that
+// only know the weather now are asked at every update instead, and the forecast is
+// the game's own model's guess.
+
+// dueWeather returns the weather for the given time: the latest forecast entry that
+// has come due, or weather from the provider if nothing is due, such as on the first
+// start. Entries that came due while no instance was leading are dropped.
+func (g *GameEngine) dueWeather(now time.Time) models.Weather {
+	// Updates don't tick exactly on time, so take entries up to half an update early
+	dueBy := now.Add(g.config.Game.WeatherUpdateInterval / 2)
+
+	previous := defaultWeather
+	if current, err := g.GetCurrentWeather(); err == nil {
+		previous = *current
+	}
+
+	if !g.weatherProvider.Ahead() {
+		if err := g.db.DB.Where("forecast_for <= ?", dueBy).Delete(&models.WeatherForecast{}).Error; err != nil {
+			log.Printf("Failed to remove past weather forecast: %v", err)
+		}
+		return g.weatherAt(previous, now)
+	}
+
+	var due models.WeatherForecast
+	err := g.db.DB.Where("forecast_for <= ?", dueBy).Order("forecast_for DESC").First(&due).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			log.Printf("Failed to load due weather forecast: %v", err)
+		}
+
+		// Whatever is forecast no longer follows from the new weather
+		if err := g.db.DB.Where("1 = 1").Delete(&models.WeatherForecast{}).Error; err != nil {
+			log.Printf("Failed to clear weather forecast: %v", err)
+		}
+		return g.weatherAt(previous, now)
+	}
+
+	if err := g.db.DB.Where("forecast_for <= ?", due.ForecastFor).Delete(&models.WeatherForecast{}).Error; err != nil {
+		log.Printf("Failed to remove used weather forecast: %v", err)
+	}
+	return g.completeWeather(forecastReadings(due), now)
+}
+
+// forecastReadings returns the weather a forecast entry predicts, without its effects
+func forecastReadings(forecast models.WeatherForecast) models.Weather {
+	return models.Weather{
+		Condition:   forecast.Condition,
+		Temperature: forecast.Temperature,
+		Humidity:    forecast.Humidity,
+		WindSpeed:   forecast.WindSpeed,
+		Event:       forecast.Event,
+	}
+}
+
+// extendForecast draws weather on from the end of the forecast until it reaches the
+// horizon, and updates every entry's probability to follow from the current weather
+func (g *GameEngine) extendForecast(current models.Weather) error {
+	interval := g.config.Game.WeatherUpdateInterval
+	if g.config.Game.ForecastHorizon <= 0 || interval <= 0 {
+		return nil
+	}
+
+	var forecasts []models.WeatherForecast
+	if err := g.db.DB.Order("forecast_for ASC").Find(&forecasts).Error; err != nil {
+		return err
+	}
+	kept := len(forecasts)
+
+	// Carry on from the last entry, or from the current weather if nothing is forecast
+	last := current
+	at := current.ValidUntil
+	if kept > 0 {
+		last = forecastReadings(forecasts[kept-1])
+		at = forecasts[kept-1].ForecastFor.Add(interval)
+	}
+
+	draw := g.weatherAt
+	if !g.weatherProvider.Ahead() {
+		draw = func(previous models.Weather, at time.Time) models.Weather {
+			weather, _ := g.weatherModel.Weather(g.ctx, previous, at)
+			return weather
+		}
+	}
+
+	horizon := time.Now().Add(g.config.Game.ForecastHorizon)
+	for ; !at.After(horizon); at = at.Add(interval) {
+		last = draw(last, at)
+		forecasts = append(forecasts, models.WeatherForecast{
+			Condition:   last.Condition,
+			Temperature: last.Temperature,
+			Humidity:    last.Humidity,
+			WindSpeed:   last.WindSpeed,
+			Event:       last.Event,
+			ForecastFor: at,
+		})
+	}
+
+	previousProbabilities := make([]int, kept)
+	for i := range forecasts[:kept] {
+		previousProbabilities[i] = forecasts[i].Probability
+	}
+	g.forecastProbabilities(current.Condition, forecasts)
+
+	return g.db.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range forecasts[:kept] {
+			if forecasts[i].Probability == previousProbabilities[i] {
+				continue
+			}
+			if err := tx.Model(&forecasts[i]).Update("probability", forecasts[i].Probability).Error; err != nil {
+				return err
+			}
+		}
+		if added := forecasts[kept:]; len(added) > 0 {
+			return tx.Create(&added).Error
+		}
+		return nil
+	})
+}
+
+// forecastProbabilities sets the chance of each forecast entry's condition given the
+// weather now. Entries must be consecutive weather updates, in order.
+func (g *GameEngine) forecastProbabilities(now models.WeatherCondition, forecasts []models.WeatherForecast) {
+	distribution := map[models.WeatherCondition]float64{now: 1}
+	for i := range forecasts {
+		distribution = g.weatherModel.stepDistribution(distribution, models.GetSeason(forecasts[i].ForecastFor))
+		// A forecast condition is always possible, however unlikely
+		forecasts[i].Probability = int(math.Max(1, math.Round(distribution[forecasts[i].Condition]*100)))
+	}
+}
+
+// GetForecast returns the forecast weather up to the given time, soonest first, and
+// warnings of the extreme weather due within the warning window. Extreme weather
+// further ahead than that isn't announced yet, so it is left out of the forecast.
+func (g *GameEngine) GetForecast(now, until time.Time) ([]models.WeatherForecast, []models.WeatherWarning, error) {
+	warnUntil := now.Add(g.config.Game.WeatherWarningWindow)
+
+	var forecasts []models.WeatherForecast
+	err := g.db.DB.Where("forecast_for <= ?", latest(until, warnUntil)).Order("forecast_for ASC").Find(&forecasts).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	warnings := []models.WeatherWarning{}
+	for i := range forecasts {
+		forecast := &forecasts[i]
+		if forecast.Event == "" {
+			continue
+		}
+		if forecast.ForecastFor.After(warnUntil) {
+			forecast.Event = ""
+			continue
+		}
+
+		// Consecutive entries with the same event make up one warning
+		endsAt := forecast.ForecastFor.Add(g.config.Game.WeatherUpdateInterval)
+		if n := len(warnings); n > 0 && warnings[n-1].Event == forecast.Event && !warnings[n-1].EndsAt.Before(forecast.ForecastFor) {
+			warnings[n-1].EndsAt = endsAt
+			continue
+		}
+		extreme := extremeEvents[forecast.Event]
+		warnings = append(warnings, models.WeatherWarning{
+			Event:      forecast.Event,
+			StartsAt:   forecast.ForecastFor,
+			EndsAt:     endsAt,
+			Greenhouse: extreme.Shelter,
+			Advice:     extreme.Advice,
+		})
+	}
+
+	n := sort.Search(len(forecasts), func(i int) bool { return forecasts[i].ForecastFor.After(until) })
+	return forecasts[:n], warnings, nil
+}
+
+// latest returns the later of two times
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,58 @@
+package game
+
+import "github.com/my-garden/api/internal/models"
+
+// Limits and pricing for garden expansion
+const (
+	StarterGardenWidth  = 3
+	StarterGardenHeight = 3
+
+	MaxGardenWidth  = 8
+	MaxGardenHeight = 8
+
+	// expansionCostPerPlot is what every plot added by an expansion costs
+	expansionCostPerPlot = 25
+)
+
+// ExpansionCost returns the coins needed to add rows and columns to a garden
+func ExpansionCost(garden *models.Garden, rows, columns int) int {
+	added := (garden.Width+columns)*(garden.Height+rows) - garden.Width*garden.Height
+	return added * expansionCostPerPlot
+}
+
+// CanExpand reports whether a garden can grow by the given rows and columns
+func CanExpand(garden *models.Garden, rows, columns int) bool {
+	return garden.Width+columns <= MaxGardenWidth && garden.Height+rows <= MaxGardenHeight
+}
+
+// DescribeGrid fills in a garden's grid metadata. The garden's plants must already be loaded.
+func DescribeGrid(garden *models.Garden) {
+	grid := &models.GardenGrid{
+		Width:         garden.Width,
+		Height:        garden.Height,
+		Size:          garden.Width * garden.Height,
+		MaxWidth:      MaxGardenWidth,
+		MaxHeight:     MaxGardenHeight,
+		FreePositions: []int{},
+	}
+	if CanExpand(garden, 1, 0) {
+		grid.RowCost = ExpansionCost(garden, 1, 0)
+	}
+	if CanExpand(garden, 0, 1) {
+		grid.ColumnCost = ExpansionCost(garden, 0, 1)
+	}
+
+	occupied := make(map[int]bool, len(garden.Plants))
+	for _, plant := range garden.Plants {
+		occupied[plant.Position] = true
+	}
+	for position := 0; position < grid.Size; position++ {
+		if occupied[position] {
+			grid.Occupied++
+		} else {
+			grid.FreePositions = append(grid.FreePositions, position)
+		}
+	}
+
+	garden.Grid = grid
+}
@@ -0,0 +1,362 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/my-garden/api/internal/models"
+)
+
+// Plants are simulated lazily: instead of stepping every plant on every tick, the
+// state since LastEvaluatedAt is integrated in one go over the weather that applied
+// during that window. Within a weather span all rates are piecewise constant, so the
+// integration only has to stop where the water or fertilizer level crosses a care
+// threshold, the plant enters a new stage or its health runs out.
+
+const (
+	// growthEpsilon absorbs floating point noise when landing exactly on a breakpoint
+	growthEpsilon = 1e-9
+
+	// bandProbe looks just past the current level to pick the band ahead
+	bandProbe = 1e-6
+)
+
+// careBand is the effect of one water or fertilizer band on a plant
+type careBand struct {
+	Growth  float64            // growth multiplier
+	Health  float64            // health change per tick
+	Regress float64            // growth progress lost per tick
+	Reason  string             // explanation shown to players
+	Cause   models.DeathReason // recorded if the band's damage kills the plant
+}
+
+// Water bands, as distance from the species' WaterNeeds
+const (
+	waterDroughtBelow = -30.0 // health declines below this
+	waterDryBelow     = -15.0 // growth penalty below this
+	waterIdealWithin  = 15.0  // growth bonus and recovery within this
+	waterLoggedAbove  = 30.0  // overwatering: roots rot above this
+)
+
+// Fertilizer bands, as distance from the species' FertilizerNeeds
+const (
+	fertilizerLowBelow  = -20.0 // growth penalty below this
+	fertilizerFedWithin = 10.0  // growth bonus within this
+	fertilizerBurnAbove = 40.0  // over-fertilizing burns the plant above this
+)
+
+var (
+	waterDrought   = careBand{Growth: 0.8, Health: -5, Reason: "drought: far too little water", Cause: models.DeathDrought}
+	waterDry       = careBand{Growth: 0.8, Reason: "soil is drying out"}
+	waterIdeal     = careBand{Growth: 1.2, Health: 2, Reason: "watered just right"}
+	waterWet       = careBand{Growth: 1.0, Reason: "a little overwatered"}
+	waterLogged    = careBand{Growth: 0.7, Health: -2, Reason: "waterlogged: roots are rotting", Cause: models.DeathRootRot}
+	fertilizerLow  = careBand{Growth: 0.85, Reason: "needs fertilizer"}
+	fertilizerOK   = careBand{Growth: 1.0, Reason: "fertilizer is adequate"}
+	fertilizerFed  = careBand{Growth: 1.25, Health: 1, Reason: "well fed"}
+	fertilizerBurn = careBand{Growth: 0.8, Health: -1, Reason: "fertilizer burn: too much fertilizer", Cause: models.DeathFertilizerBurn}
+)
+
+// fertilizerDecayPerTick is how much fertilizer a plant uses up each tick
+const fertilizerDecayPerTick = 1.0
+
+// waterCare returns the band a water level falls in for a species
+func waterCare(level float64, needs int) careBand {
+	deviation := level - float64(needs)
+	switch {
+	case deviation < waterDroughtBelow:
+		return waterDrought
+	case deviation < waterDryBelow:
+		return waterDry
+	case deviation <= waterIdealWithin:
+		return waterIdeal
+	case deviation <= waterLoggedAbove:
+		return waterWet
+	default:
+		return waterLogged
+	}
+}
+
+// waterThresholds are the water levels at which waterCare changes band
+func waterThresholds(needs int) []float64 {
+	n := float64(needs)
+	return []float64{n + waterLoggedAbove, n + waterIdealWithin, n + waterDryBelow, n + waterDroughtBelow, 0}
+}
+
+// fertilizerCare returns the band a fertilizer level falls in for a species
+func fertilizerCare(level float64, needs int) careBand {
+	deviation := level - float64(needs)
+	switch {
+	case deviation > fertilizerBurnAbove:
+		return fertilizerBurn
+	case needs == 0:
+		// Species that don't need feeding neither gain nor suffer from a little
+		return fertilizerOK
+	case math.Abs(deviation) <= fertilizerFedWithin:
+		return fertilizerFed
+	case deviation < fertilizerLowBelow:
+		return fertilizerLow
+	default:
+		return fertilizerOK
+	}
+}
+
+// fertilizerThresholds are the fertilizer levels at which fertilizerCare changes band
+func fertilizerThresholds(needs int) []float64 {
+	n := float64(needs)
+	return []float64{n + fertilizerBurnAbove, n + fertilizerFedWithin, n - fertilizerFedWithin, n + fertilizerLowBelow, 0}
+}
+
+// stepToThreshold limits step to the time a falling level needs to reach the next threshold
+func stepToThreshold(step, level, rate float64, thresholds []float64) float64 {
+	if rate <= 0 {
+		return step
+	}
+	for _, threshold := range thresholds {
+		if level > threshold {
+			step = math.Min(step, (level-threshold)/rate)
+		}
+	}
+	return step
+}
+
+// GrowthState is the part of a plant a growth model evolves
+type GrowthState struct {
+	Stage           models.PlantStage
+	GrowthProgress  float64
+	WaterLevel      float64
+	FertilizerLevel float64
+	Health          float64
+	Care            models.CareHistory
+
+	// Unused is how much of the time given to Advance was left when the plant
+	// stopped growing, so callers can tell when it ripened or withered
+	Unused time.Duration
+	// Cause is what killed the plant, set by Advance when it withers
+	Cause models.DeathReason
+}
+
+// NewGrowthState captures the simulated state of a plant
+func NewGrowthState(plant *models.Plant) GrowthState {
+	health, water, fertilizer := plant.Levels()
+	return GrowthState{
+		Stage:           plant.Stage,
+		GrowthProgress:  plant.GrowthProgress,
+		WaterLevel:      water,
+		FertilizerLevel: fertilizer,
+		Health:          health,
+		Care:            plant.Care,
+	}
+}
+
+// Apply writes the state back onto a plant
+func (s GrowthState) Apply(plant *models.Plant) {
+	plant.Stage = s.Stage
+	plant.GrowthProgress = s.GrowthProgress
+	plant.SetLevels(s.Health, s.WaterLevel, s.FertilizerLevel)
+	plant.Care = s.Care
+}
+
+// Growing reports whether the simulation still changes the plant
+func (s GrowthState) Growing() bool {
+	return s.Stage != models.PlantStageHarvestable && s.Stage != models.PlantStageWithered
+}
+
+// Environment is what a plant is exposed to over a span of time
+type Environment struct {
+	Weather *models.Weather
+	Season  models.Season
+
+	// Garden upgrades that change how the plant experiences the weather
+	Sprinkler  bool
+	Greenhouse bool
+}
+
+// GrowthModel computes how a plant evolves over a span of constant conditions.
+// Implementations must set Unused on the returned state and be safe for concurrent use.
+type GrowthModel interface {
+	Advance(state GrowthState, plantType *models.PlantType, env Environment, elapsed time.Duration) GrowthState
+}
+
+// StageThresholds are the growth progress values at which a plant enters each stage
+type StageThresholds struct {
+	Sprout      float64
+	Growing     float64
+	Mature      float64
+	Harvestable float64
+}
+
+// DefaultStageThresholds are the classic 20/40/70/100 cutoffs
+var DefaultStageThresholds = StageThresholds{Sprout: 20, Growing: 40, Mature: 70, Harvestable: 100}
+
+// Stage returns the stage a plant is in at the given progress
+func (t StageThresholds) Stage(progress float64) models.PlantStage {
+	switch {
+	case progress < t.Sprout:
+		return models.PlantStageSeed
+	case progress < t.Growing:
+		return models.PlantStageSprout
+	case progress < t.Mature:
+		return models.PlantStageGrowing
+	case progress < t.Harvestable:
+		return models.PlantStageMature
+	default:
+		return models.PlantStageHarvestable
+	}
+}
+
+// start returns the progress at which the plant entered its current stage
+func (t StageThresholds) start(progress float64) float64 {
+	start := 0.0
+	for _, threshold := range []float64{t.Sprout, t.Growing, t.Mature} {
+		if progress >= threshold {
+			start = threshold
+		}
+	}
+	return start
+}
+
+// next returns the progress at which the plant leaves its current stage
+func (t StageThresholds) next(progress float64) float64 {
+	for _, threshold := range []float64{t.Sprout, t.Growing, t.Mature} {
+		if progress < threshold {
+			return threshold
+		}
+	}
+	return t.Harvestable
+}
+
+// StagedGrowthModel grows plants at a speed that may differ per stage, which gives
+// plant families their own growth curve. Water and fertilizer care apply the same
+// way regardless of stage.
+type StagedGrowthModel struct {
+	// Tick is the interval the per-tick health changes are expressed in
+	Tick   time.Duration
+	Stages StageThresholds
+	// StageSpeed scales growth in a stage. Stages that are missing grow at normal speed.
+	StageSpeed map[models.PlantStage]float64
+}
+
+// NewDefaultGrowthModel grows evenly through the classic stage cutoffs
+func NewDefaultGrowthModel(tick time.Duration) *StagedGrowthModel {
+	return &StagedGrowthModel{
+		Tick:   tick,
+		Stages: DefaultStageThresholds,
+	}
+}
+
+func (m *StagedGrowthModel) speed(stage models.PlantStage) float64 {
+	if speed, ok := m.StageSpeed[stage]; ok {
+		return speed
+	}
+	return 1.0
+}
+
+// Advance integrates a plant's state over a span of constant conditions
+func (m *StagedGrowthModel) Advance(state GrowthState, plantType *models.PlantType, env Environment, elapsed time.Duration) GrowthState {
+	minutes := elapsed.Minutes()
+	tickMinutes := m.Tick.Minutes()
+	state.Unused = 0
+	if minutes <= 0 || tickMinutes <= 0 || plantType.GrowthTime <= 0 {
+		return state
+	}
+
+	climate := climateEffect(env)
+	season := seasonPreference(plantType, env.Season)
+	weather := weatherPreference(plantType, env.Weather.Condition)
+	temperature := temperatureEffect(plantType, env)
+	event, _ := eventEffect(env)
+	baseGrowthRate := climate.Growth * temperature.Growth * season.Growth * weather.Growth * event.Growth / float64(plantType.GrowthTime) // per minute
+	baseHealthRate := climate.Health + temperature.Health + season.Health + weather.Health + event.Health
+	regressRate := event.Regress / tickMinutes
+	evaporation := evaporationPerMinute(env.Weather, tickMinutes)
+	fertilizerDecay := fertilizerDecayPerTick / tickMinutes
+	waterLimits := waterThresholds(plantType.WaterNeeds)
+	fertilizerLimits := fertilizerThresholds(plantType.FertilizerNeeds)
+	if env.Sprinkler {
+		waterLimits = append(waterLimits, sprinklerLevel(plantType.WaterNeeds))
+	}
+
+	for minutes > growthEpsilon && state.Growing() {
+		if env.Sprinkler && state.WaterLevel <= sprinklerLevel(plantType.WaterNeeds)+growthEpsilon {
+			state.WaterLevel = math.Min(100, sprinklerTarget(plantType.WaterNeeds))
+		}
+
+		// Rates hold for the interval ahead, so while a level is falling a value sitting
+		// exactly on a threshold already belongs to the band below it
+		waterLevel := state.WaterLevel
+		if evaporation > 0 {
+			waterLevel -= bandProbe
+		}
+		fertilizerLevel := state.FertilizerLevel
+		if fertilizerLevel > 0 {
+			fertilizerLevel -= bandProbe
+		}
+		water := waterCare(waterLevel, plantType.WaterNeeds)
+		fertilizer := fertilizerCare(fertilizerLevel, plantType.FertilizerNeeds)
+
+		growthRate := baseGrowthRate*water.Growth*fertilizer.Growth*m.speed(state.Stage) - regressRate
+		if growthRate < 0 && state.GrowthProgress <= growthEpsilon {
+			// Nothing left to lose
+			growthRate = 0
+		}
+		healthRate := (baseHealthRate + water.Health + fertilizer.Health) / tickMinutes
+
+		// Advance to the next point where a rate changes or the plant changes stage
+		step := minutes
+		step = stepToThreshold(step, state.WaterLevel, evaporation, waterLimits)
+		if state.FertilizerLevel > 0 {
+			step = stepToThreshold(step, state.FertilizerLevel, fertilizerDecay, fertilizerLimits)
+		}
+		if growthRate > 0 {
+			step = math.Min(step, (m.Stages.next(state.GrowthProgress)-state.GrowthProgress)/growthRate)
+		} else if growthRate < 0 {
+			// Extreme weather is knocking the plant back towards the previous stage
+			step = math.Min(step, (state.GrowthProgress-m.Stages.start(state.GrowthProgress))/-growthRate)
+		}
+		if healthRate < 0 {
+			step = math.Min(step, state.Health/-healthRate)
+		} else if healthRate > 0 && state.Health < 100 {
+			step = math.Min(step, (100-state.Health)/healthRate)
+		}
+		step = math.Max(step, growthEpsilon)
+
+		health := state.Health
+		state.GrowthProgress = math.Max(0, state.GrowthProgress+growthRate*step)
+		state.WaterLevel = math.Max(0, state.WaterLevel-evaporation*step)
+		state.FertilizerLevel = math.Max(0, state.FertilizerLevel-fertilizerDecay*step)
+		state.Health = math.Min(100, math.Max(0, state.Health+healthRate*step))
+		recordCare(&state.Care, step, health, state.Health, water, fertilizer, plantType)
+		minutes -= step
+
+		state.Stage = m.Stages.Stage(state.GrowthProgress + growthEpsilon)
+		if state.Health <= growthEpsilon {
+			state.Health = 0
+			state.Stage = models.PlantStageWithered
+			state.Cause = deathCause(climate, temperature, season, weather, event, water, fertilizer)
+		}
+	}
+
+	if minutes > growthEpsilon {
+		state.Unused = time.Duration(minutes * float64(time.Minute))
+	}
+	return state
+}
+
+// deathCause blames the most damaging of the bands acting on a plant when it died
+func deathCause(bands ...careBand) models.DeathReason {
+	var cause models.DeathReason
+	worst := 0.0
+	for _, band := range bands {
+		if band.Cause != "" && band.Health < worst {
+			cause, worst = band.Cause, band.Health
+		}
+	}
+	return cause
+}
+
+// evaporationPerMinute spreads the whole-unit water loss of one tick over its duration
+func evaporationPerMinute(weather *models.Weather, tickMinutes float64) float64 {
+	perTick := math.Trunc(weather.WaterEvaporationRate * tickMinutes / 60.0 * 10)
+	return perTick / tickMinutes
+}
@@ -0,0 +1,81 @@
+package game
+
+import (
+	"log"
+	"time"
+
+	"github.com/my-garden/api/internal/models"
+)
+
+// Names of the growth models shipped with the game. PlantType.GrowthModel selects one.
+const (
+	GrowthModelDefault = "default"
+	GrowthModelTree    = "tree"
+	GrowthModelHerb    = "herb"
+	GrowthModelVine    = "vine"
+)
+
+// builtinGrowthModels returns the growth curves available out of the box
+func builtinGrowthModels(tick time.Duration) map[string]GrowthModel {
+	return map[string]GrowthModel{
+		GrowthModelDefault: NewDefaultGrowthModel(tick),
+
+		// Trees take a long time to establish, then put on most of their growth at once
+		GrowthModelTree: &StagedGrowthModel{
+			Tick:   tick,
+			Stages: StageThresholds{Sprout: 10, Growing: 30, Mature: 65, Harvestable: 100},
+			StageSpeed: map[models.PlantStage]float64{
+				models.PlantStageSeed:    0.5,
+				models.PlantStageSprout:  0.7,
+				models.PlantStageGrowing: 1.3,
+				models.PlantStageMature:  1.1,
+			},
+		},
+
+		// Herbs shoot up quickly and then slow down as they mature
+		GrowthModelHerb: &StagedGrowthModel{
+			Tick:   tick,
+			Stages: StageThresholds{Sprout: 25, Growing: 50, Mature: 75, Harvestable: 100},
+			StageSpeed: map[models.PlantStage]float64{
+				models.PlantStageSeed:    1.4,
+				models.PlantStageSprout:  1.2,
+				models.PlantStageGrowing: 0.9,
+				models.PlantStageMature:  0.7,
+			},
+		},
+
+		// Vines start slowly, then climb fast through the growing stage
+		GrowthModelVine: &StagedGrowthModel{
+			Tick:   tick,
+			Stages: StageThresholds{Sprout: 15, Growing: 35, Mature: 80, Harvestable: 100},
+			StageSpeed: map[models.PlantStage]float64{
+				models.PlantStageSeed:    0.8,
+				models.PlantStageSprout:  0.9,
+				models.PlantStageGrowing: 1.4,
+				models.PlantStageMature:  0.8,
+			},
+		},
+	}
+}
+
+// RegisterGrowthModel makes a growth model available to plant types under the given
+// name, replacing any model already registered under it
+func (g *GameEngine) RegisterGrowthModel(name string, model GrowthModel) {
+	g.growthModelsMu.Lock()
+	defer g.growthModelsMu.Unlock()
+	g.growthModels[name] = model
+}
+
+// growthModelFor returns the growth model a plant type selected, falling back to the default
+func (g *GameEngine) growthModelFor(plantType *models.PlantType) GrowthModel {
+	g.growthModelsMu.RLock()
+	defer g.growthModelsMu.RUnlock()
+
+	if model, ok := g.growthModels[plantType.GrowthModel]; ok {
+		return model
+	}
+	if plantType.GrowthModel != "" {
+		log.Printf("Unknown growth model %q for plant type %s, using default", plantType.GrowthModel, plantType.Name)
+	}
+	return g.growthModels[GrowthModelDefault]
+}
@@ -0,0 +1,191 @@
+package game
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/my-garden/api/internal/models"
+)
+
+// How far lazy evaluation may drift from stepping tick by tick. Stepping only looks at
+// the levels at the start of each tick, so the two can disagree by up to about a
+// tick's worth of change around every band crossing.
+const (
+	progressEpsilon   = 1.0 // growth progress points
+	healthEpsilon     = 5.0 // health points
+	waterEpsilon      = 1.0 // water level points
+	fertilizerEpsilon = 1.0 // fertilizer level points
+	timeEpsilon       = 5 * time.Minute
+)
+
+// tickByTick is the per-tick simulation: the levels at the start of each tick decide
+// its rates, and the whole tick is applied at once. It returns the state and how long
+// the plant kept growing.
+func tickByTick(m *StagedGrowthModel, state GrowthState, plantType *models.PlantType, env Environment, elapsed time.Duration) (GrowthState, time.Duration) {
+	tick := m.Tick.Minutes()
+	climate := climateEffect(env)
+	temperature := temperatureEffect(plantType, env)
+	season := seasonPreference(plantType, env.Season)
+	weather := weatherPreference(plantType, env.Weather.Condition)
+	growthPerTick := climate.Growth * temperature.Growth * season.Growth * weather.Growth / float64(plantType.GrowthTime) * tick
+	healthPerTick := climate.Health + temperature.Health + season.Health + weather.Health
+	evaporationPerTick := math.Trunc(env.Weather.WaterEvaporationRate * tick / 60.0 * 10)
+
+	ticks := int(elapsed / m.Tick)
+	grew := time.Duration(0)
+	for i := 0; i < ticks && state.Growing(); i++ {
+		water := waterCare(state.WaterLevel, plantType.WaterNeeds)
+		fertilizer := fertilizerCare(state.FertilizerLevel, plantType.FertilizerNeeds)
+
+		// A ripe plant stops growing
+		state.GrowthProgress = math.Min(m.Stages.Harvestable, state.GrowthProgress+growthPerTick*water.Growth*fertilizer.Growth*m.speed(state.Stage))
+		state.WaterLevel = math.Max(0, state.WaterLevel-evaporationPerTick)
+		state.FertilizerLevel = math.Max(0, state.FertilizerLevel-fertilizerDecayPerTick)
+		state.Health = math.Min(100, math.Max(0, state.Health+healthPerTick+water.Health+fertilizer.Health))
+		state.Stage = m.Stages.Stage(state.GrowthProgress)
+		if state.Health <= 0 {
+			state.Stage = models.PlantStageWithered
+		}
+		grew += m.Tick
+	}
+	return state, grew
+}
+
+func TestAdvanceMatchesTickByTick(t *testing.T) {
+	sunny := &models.Weather{Condition: models.WeatherSunny, Temperature: 20, GrowthMultiplier: 1.2, WaterEvaporationRate: 1.5}
+	cloudy := &models.Weather{Condition: models.WeatherCloudy, Temperature: 18, GrowthMultiplier: 1.0, WaterEvaporationRate: 1.0}
+
+	plantType := func(growthTime, waterNeeds, fertilizerNeeds int) *models.PlantType {
+		return &models.PlantType{
+			GrowthTime:      growthTime,
+			WaterNeeds:      waterNeeds,
+			FertilizerNeeds: fertilizerNeeds,
+			Season:          "all",
+			Weather:         "all",
+			OptimalTempMin:  15,
+			OptimalTempMax:  25,
+			FrostThreshold:  2,
+			HeatThreshold:   32,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		plantType *models.PlantType
+		weather   *models.Weather
+		state     GrowthState
+		elapsed   time.Duration
+		wantStage models.PlantStage
+	}{
+		{
+			name:      "steady ideal care",
+			plantType: plantType(20, 50, 20),
+			weather:   cloudy,
+			state:     GrowthState{Stage: models.PlantStageSeed, WaterLevel: 55, FertilizerLevel: 40, Health: 80},
+			elapsed:   90 * time.Minute,
+			wantStage: models.PlantStageSeed,
+		},
+		{
+			name:      "water dries out through the dry band into drought",
+			plantType: plantType(20, 60, 0),
+			weather:   sunny,
+			state:     GrowthState{Stage: models.PlantStageSprout, GrowthProgress: 25, WaterLevel: 70, Health: 100},
+			elapsed:   4 * time.Hour,
+			wantStage: models.PlantStageSprout,
+		},
+		{
+			name:      "fertilizer burn wears off until it is well fed",
+			plantType: plantType(30, 50, 10),
+			weather:   cloudy,
+			state:     GrowthState{Stage: models.PlantStageGrowing, GrowthProgress: 45, WaterLevel: 50, FertilizerLevel: 70, Health: 100},
+			elapsed:   5 * time.Hour,
+			wantStage: models.PlantStageGrowing,
+		},
+		{
+			name:      "grows through several stages",
+			plantType: plantType(2, 50, 0),
+			weather:   cloudy,
+			state:     GrowthState{Stage: models.PlantStageSeed, WaterLevel: 60, Health: 100},
+			elapsed:   90 * time.Minute,
+			wantStage: models.PlantStageGrowing,
+		},
+		{
+			name:      "ripens",
+			plantType: plantType(1, 50, 0),
+			weather:   cloudy,
+			state:     GrowthState{Stage: models.PlantStageMature, GrowthProgress: 75, WaterLevel: 55, Health: 100},
+			elapsed:   3 * time.Hour,
+			wantStage: models.PlantStageHarvestable,
+		},
+		{
+			name:      "dies of drought",
+			plantType: plantType(20, 60, 0),
+			weather:   sunny,
+			state:     GrowthState{Stage: models.PlantStageSprout, GrowthProgress: 25, WaterLevel: 20, Health: 60},
+			elapsed:   4 * time.Hour,
+			wantStage: models.PlantStageWithered,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewDefaultGrowthModel(5 * time.Minute)
+			env := Environment{Weather: tt.weather, Season: models.SeasonSummer}
+
+			want, wantGrew := tickByTick(model, tt.state, tt.plantType, env, tt.elapsed)
+			got := model.Advance(tt.state, tt.plantType, env, tt.elapsed)
+			gotGrew := tt.elapsed - got.Unused
+
+			if want.Stage != tt.wantStage {
+				t.Fatalf("tick by tick ended in stage %s, the case expects %s", want.Stage, tt.wantStage)
+			}
+			if got.Stage != want.Stage {
+				t.Errorf("stage = %s, tick by tick %s", got.Stage, want.Stage)
+			}
+			if diff := math.Abs(got.GrowthProgress - want.GrowthProgress); diff > progressEpsilon {
+				t.Errorf("progress = %.2f, tick by tick %.2f", got.GrowthProgress, want.GrowthProgress)
+			}
+			if diff := math.Abs(got.Health - want.Health); diff > healthEpsilon {
+				t.Errorf("health = %.2f, tick by tick %.2f", got.Health, want.Health)
+			}
+			if diff := math.Abs(got.WaterLevel - want.WaterLevel); diff > waterEpsilon {
+				t.Errorf("water = %.2f, tick by tick %.2f", got.WaterLevel, want.WaterLevel)
+			}
+			if diff := math.Abs(got.FertilizerLevel - want.FertilizerLevel); diff > fertilizerEpsilon {
+				t.Errorf("fertilizer = %.2f, tick by tick %.2f", got.FertilizerLevel, want.FertilizerLevel)
+			}
+			if diff := gotGrew - wantGrew; diff > timeEpsilon || diff < -timeEpsilon {
+				t.Errorf("grew for %s, tick by tick %s", gotGrew, wantGrew)
+			}
+		})
+	}
+}
+
+func TestFrequentEvaluationKeepsFractions(t *testing.T) {
+	model := NewDefaultGrowthModel(5 * time.Minute)
+	plantType := &models.PlantType{GrowthTime: 600, WaterNeeds: 60, FertilizerNeeds: 20, Season: "all", Weather: "all",
+		OptimalTempMin: 15, OptimalTempMax: 25, FrostThreshold: 2, HeatThreshold: 32}
+	env := Environment{
+		Weather: &models.Weather{Condition: models.WeatherSunny, Temperature: 20, GrowthMultiplier: 1.2, WaterEvaporationRate: 1.5},
+		Season:  models.SeasonSummer,
+	}
+
+	// Evaluating every minute loses less than a unit each time, which rounding the
+	// stored levels would throw away
+	often := &models.Plant{Stage: models.PlantStageSeed, Health: 90, WaterLevel: 60, FertilizerLevel: 20}
+	for i := 0; i < 60; i++ {
+		model.Advance(NewGrowthState(often), plantType, env, time.Minute).Apply(often)
+	}
+
+	once := &models.Plant{Stage: models.PlantStageSeed, Health: 90, WaterLevel: 60, FertilizerLevel: 20}
+	model.Advance(NewGrowthState(once), plantType, env, time.Hour).Apply(once)
+
+	if often.Health != once.Health || often.WaterLevel != once.WaterLevel || often.FertilizerLevel != once.FertilizerLevel {
+		t.Errorf("evaluated every minute: health %d, water %d, fertilizer %d; once: health %d, water %d, fertilizer %d",
+			often.Health, often.WaterLevel, often.FertilizerLevel, once.Health, once.WaterLevel, once.FertilizerLevel)
+	}
+	if once.WaterLevel != 48 {
+		t.Errorf("water = %d after an hour of sunshine, want 48", once.WaterLevel)
+	}
+}
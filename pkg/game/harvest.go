@@ -0,0 +1,79 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/my-garden/api/internal/models"
+)
+
+// NewHarvestRecord captures a plant's lifecycle and the conditions it was harvested in.
+// Call it before the plant is updated for the harvest; the caller fills in the rewards.
+// Weather may be nil if none is known.
+func NewHarvestRecord(userID uuid.UUID, plant *models.Plant, weather *models.Weather, harvestedAt time.Time) models.HarvestRecord {
+	// Perennials grow back from their previous harvest
+	grownFrom := plant.PlantedAt
+	if plant.HarvestedAt != nil {
+		grownFrom = *plant.HarvestedAt
+	}
+
+	// Kept to a tenth, as the harvest history exports them
+	health, water, fertilizer := plant.Levels()
+	record := models.HarvestRecord{
+		UserID:                userID,
+		GardenID:              plant.GardenID,
+		PlantID:               plant.ID,
+		PlantTypeID:           plant.PlantTypeID,
+		Position:              plant.Position,
+		HarvestNumber:         plant.HarvestCount + 1,
+		PlantedAt:             plant.PlantedAt,
+		HarvestedAt:           harvestedAt,
+		GrowthDurationSeconds: int64(harvestedAt.Sub(grownFrom).Seconds()),
+		Health:                math.Round(health*10) / 10,
+		WaterLevel:            math.Round(water*10) / 10,
+		FertilizerLevel:       math.Round(fertilizer*10) / 10,
+		Season:                models.GetSeason(harvestedAt),
+	}
+	if weather != nil {
+		temperature := weather.Temperature
+		record.WeatherCondition = weather.Condition
+		record.Temperature = &temperature
+	}
+	return record
+}
+
+// FinishHarvest updates a plant that has just been harvested. Perennials with harvests
+// left go back to their regrow stage with a fresh care history, and the tick's sweep
+// grows them back like any other plant; everything else withers. It reports whether
+// the plant is regrowing.
+func FinishHarvest(plant *models.Plant, harvestedAt time.Time) bool {
+	plant.HarvestCount++
+	plant.HarvestedAt = &harvestedAt
+	plant.LastEvaluatedAt = harvestedAt
+	plant.Ripeness = nil
+
+	if plant.HarvestCount >= plant.PlantType.MaxHarvests {
+		plant.Stage = models.PlantStageWithered
+		plant.WitheredAt = &harvestedAt
+		plant.DeathReason = models.DeathHarvested
+		return false
+	}
+
+	plant.Stage = plant.PlantType.RegrowStage
+	if plant.Stage == "" {
+		plant.Stage = models.PlantStageSeed
+	}
+	plant.GrowthProgress = plant.PlantType.RegrowProgress
+	plant.RipenedAt = nil
+	plant.Care = models.CareHistory{}
+	return true
+}
+
+// HarvestsLeft returns how many more times a plant can be harvested
+func HarvestsLeft(plant *models.Plant) int {
+	if left := plant.PlantType.MaxHarvests - plant.HarvestCount; left > 0 {
+		return left
+	}
+	return 0
+}
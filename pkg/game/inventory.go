@@ -0,0 +1,51 @@
+package game
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/my-garden/api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNotEnoughItems is returned when a user tries to use more of an item than they own
+var ErrNotEnoughItems = errors.New("not enough items in inventory")
+
+// ConsumeExperience is the experience a player gains for each harvested item they consume
+const ConsumeExperience = 1
+
+// AddToInventory puts harvested goods into a user's inventory, stacked by quality.
+// Pass a transaction to make it part of a larger change.
+func AddToInventory(tx *gorm.DB, userID, plantTypeID uuid.UUID, quality models.HarvestQuality, quantity int) error {
+	item := models.InventoryItem{
+		UserID:      userID,
+		PlantTypeID: plantTypeID,
+		Quality:     quality,
+		Quantity:    quantity,
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "plant_type_id"}, {Name: "quality"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"quantity": gorm.Expr("inventory_items.quantity + EXCLUDED.quantity")}),
+	}).Create(&item).Error
+}
+
+// TakeFromInventory removes goods from a user's inventory, failing with
+// ErrNotEnoughItems rather than letting the quantity go negative
+func TakeFromInventory(tx *gorm.DB, userID, plantTypeID uuid.UUID, quality models.HarvestQuality, quantity int) error {
+	result := tx.Model(&models.InventoryItem{}).
+		Where("user_id = ? AND plant_type_id = ? AND quality = ? AND quantity >= ?", userID, plantTypeID, quality, quantity).
+		UpdateColumn("quantity", gorm.Expr("quantity - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotEnoughItems
+	}
+	return nil
+}
+
+// SalePrice is what a user gets for selling harvested goods of some quality
+func SalePrice(plantType *models.PlantType, quality models.HarvestQuality, quantity int) int {
+	return scaleReward(plantType.HarvestValue, rewardFor(quality).Price) * quantity
+}
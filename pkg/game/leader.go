@@ -0,0 +1,170 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const leaderKey = "game:leader"
+
+// renewLeaseScript extends the lease only if we still hold it
+var renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLeaseScript deletes the lease only if we still hold it
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// LeaderInfo describes the current lease holder
+type LeaderInfo struct {
+	InstanceID string `json:"instance_id"`
+	LeaderID   string `json:"leader_id"`
+	IsLeader   bool   `json:"is_leader"`
+}
+
+// LeaderElector holds a Redis lease so only one replica runs the simulation loops.
+// The lease expires on its own if the holder dies, letting another replica take over.
+type LeaderElector struct {
+	redis    *redis.Client
+	id       string
+	ttl      time.Duration
+	mu       sync.RWMutex
+	isLeader bool
+}
+
+func NewLeaderElector(redis *redis.Client, id string, ttl time.Duration) *LeaderElector {
+	return &LeaderElector{
+		redis: redis,
+		id:    id,
+		ttl:   ttl,
+	}
+}
+
+// Run campaigns for leadership until ctx is cancelled. lead is started in its own
+// goroutine every time the lease is won, and its context is cancelled when it is lost.
+// lead must return once its context is cancelled; the lease is only given up after it
+// has, so two replicas never lead at once.
+func (e *LeaderElector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	var stopLead func()
+	stepDown := func() {
+		if stopLead != nil {
+			stopLead()
+			stopLead = nil
+		}
+		e.setLeader(false)
+	}
+
+	for {
+		held, err := e.campaign(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			// Leadership lives in Redis, so while it is unreachable no replica leads
+			log.Printf("Leader election failed, the game has no leader until Redis is reachable: %v", err)
+		}
+
+		switch {
+		case held && stopLead == nil:
+			log.Printf("Instance %s acquired game leadership", e.id)
+			stopLead = e.startLeading(ctx, lead)
+		case !held && stopLead != nil:
+			log.Printf("Instance %s lost game leadership", e.id)
+			stepDown()
+		}
+
+		select {
+		case <-ctx.Done():
+			stepDown()
+			e.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// startLeading marks this instance as leader and runs lead until the returned stop is
+// called, which waits for lead to return
+func (e *LeaderElector) startLeading(ctx context.Context, lead func(ctx context.Context)) func() {
+	e.setLeader(true)
+
+	leadCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(leadCtx)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// campaign renews the lease if we hold it, or acquires it if it is free. A lease we
+// still hold after stepping down, such as when Redis briefly failed to confirm a
+// renewal, is taken straight back rather than waiting for it to expire.
+func (e *LeaderElector) campaign(ctx context.Context) (bool, error) {
+	renewed, err := renewLeaseScript.Run(ctx, e.redis, []string{leaderKey}, e.id, e.ttl.Milliseconds()).Int()
+	if err != nil {
+		// Without a confirmed renewal we can't be sure nobody else took over
+		return false, err
+	}
+	if renewed == 1 {
+		return true, nil
+	}
+
+	return e.redis.SetNX(ctx, leaderKey, e.id, e.ttl).Result()
+}
+
+// release gives up the lease so another replica can take over without waiting for expiry
+func (e *LeaderElector) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := releaseLeaseScript.Run(ctx, e.redis, []string{leaderKey}, e.id).Err(); err != nil {
+		log.Printf("Failed to release game leadership: %v", err)
+	}
+}
+
+func (e *LeaderElector) setLeader(isLeader bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.isLeader = isLeader
+}
+
+// IsLeader reports whether this instance currently holds the lease
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isLeader
+}
+
+// Info looks up the current lease holder
+func (e *LeaderElector) Info(ctx context.Context) (LeaderInfo, error) {
+	info := LeaderInfo{
+		InstanceID: e.id,
+		IsLeader:   e.IsLeader(),
+	}
+
+	leaderID, err := e.redis.Get(ctx, leaderKey).Result()
+	if err != nil && err != redis.Nil {
+		return info, err
+	}
+	info.LeaderID = leaderID
+
+	return info, nil
+}
@@ -0,0 +1,489 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/my-garden/api/internal/database"
+	"github.com/my-garden/api/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// LeaderboardBoard identifies what a leaderboard ranks players by
+type LeaderboardBoard string
+
+const (
+	BoardExperience LeaderboardBoard = "experience"
+	BoardCoins      LeaderboardBoard = "coins"
+	BoardHarvests   LeaderboardBoard = "harvests"
+)
+
+// PlantTypeBoard ranks players by harvests of a single plant type
+func PlantTypeBoard(plantTypeID uuid.UUID) LeaderboardBoard {
+	return LeaderboardBoard(string(BoardHarvests) + ":" + plantTypeID.String())
+}
+
+// LeaderboardWindow is the stretch of time a leaderboard covers
+type LeaderboardWindow string
+
+const (
+	WindowAllTime  LeaderboardWindow = "all_time"
+	WindowWeekly   LeaderboardWindow = "weekly"
+	WindowSeasonal LeaderboardWindow = "seasonal"
+)
+
+// LeaderboardWindows lists every window, in display order
+var LeaderboardWindows = []LeaderboardWindow{WindowAllTime, WindowWeekly, WindowSeasonal}
+
+const leaderboardKeyPrefix = "leaderboard:"
+
+// Start returns when the window containing t began. All-time windows have no start.
+func (w LeaderboardWindow) Start(t time.Time) time.Time {
+	t = t.UTC()
+	switch w {
+	case WindowWeekly:
+		// Weeks start on Monday
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+	case WindowSeasonal:
+		return nextSeasonStart(t).AddDate(0, -3, 0)
+	default:
+		return time.Time{}
+	}
+}
+
+// End returns when the window containing t closes. All-time windows never close.
+func (w LeaderboardWindow) End(t time.Time) time.Time {
+	switch w {
+	case WindowWeekly:
+		return w.Start(t).AddDate(0, 0, 7)
+	case WindowSeasonal:
+		return nextSeasonStart(t.UTC())
+	default:
+		return time.Time{}
+	}
+}
+
+// Period names the window containing t, such as "2024-W05" or "2024-winter"
+func (w LeaderboardWindow) Period(t time.Time) string {
+	t = t.UTC()
+	switch w {
+	case WindowWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case WindowSeasonal:
+		start := w.Start(t)
+		return fmt.Sprintf("%d-%s", start.Year(), models.GetSeason(start))
+	default:
+		return "all"
+	}
+}
+
+// Valid reports whether w is a known window
+func (w LeaderboardWindow) Valid() bool {
+	for _, window := range LeaderboardWindows {
+		if w == window {
+			return true
+		}
+	}
+	return false
+}
+
+// LeaderboardEntry is one ranked player
+type LeaderboardEntry struct {
+	Rank     int64     `json:"rank"`
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Level    int       `json:"level"`
+	Score    int64     `json:"score"`
+}
+
+// LeaderboardPage is a slice of a leaderboard
+type LeaderboardPage struct {
+	Board   LeaderboardBoard   `json:"board"`
+	Window  LeaderboardWindow  `json:"window"`
+	Period  string             `json:"period"`
+	Page    int                `json:"page"`
+	Limit   int                `json:"limit"`
+	Total   int64              `json:"total"`
+	Entries []LeaderboardEntry `json:"entries"`
+}
+
+// LeaderboardRank is a single player's standing on a leaderboard
+type LeaderboardRank struct {
+	Board  LeaderboardBoard  `json:"board"`
+	Window LeaderboardWindow `json:"window"`
+	Period string            `json:"period"`
+	Rank   *int64            `json:"rank"` // nil when the player isn't ranked yet
+	Score  int64             `json:"score"`
+	Total  int64             `json:"total"`
+}
+
+// Leaderboard maintains player rankings in Redis sorted sets. Every window has its own
+// key per period, so weekly and seasonal boards start empty when a new period begins.
+type Leaderboard struct {
+	db    *database.Database
+	redis *redis.Client
+}
+
+func NewLeaderboard(db *database.Database, redis *redis.Client) *Leaderboard {
+	return &Leaderboard{
+		db:    db,
+		redis: redis,
+	}
+}
+
+func leaderboardKey(window LeaderboardWindow, period string, board LeaderboardBoard) string {
+	return leaderboardKeyPrefix + string(window) + ":" + period + ":" + string(board)
+}
+
+// HandleEvent keeps the leaderboards up to date as players harvest, sell and consume goods
+func (l *Leaderboard) HandleEvent(ctx context.Context, event Event) {
+	switch event.Type {
+	case EventHarvestCompleted:
+		var data HarvestEventData
+		if err := event.Decode(&data); err != nil {
+			log.Printf("Failed to decode harvest event %s: %v", event.ID, err)
+			return
+		}
+		if err := l.RecordHarvest(ctx, event.UserID, data, event.OccurredAt); err != nil {
+			log.Printf("Failed to update leaderboards for harvest %s: %v", event.ID, err)
+		}
+
+	case EventItemsSold, EventItemsConsumed:
+		var data InventoryEventData
+		if err := event.Decode(&data); err != nil {
+			log.Printf("Failed to decode inventory event %s: %v", event.ID, err)
+			return
+		}
+		if err := l.RecordRewards(ctx, event.UserID, data.CoinsEarned, data.ExperienceEarned, event.OccurredAt); err != nil {
+			log.Printf("Failed to update leaderboards for %s %s: %v", event.Type, event.ID, err)
+		}
+	}
+}
+
+// RecordHarvest adds a harvest to every leaderboard it counts towards
+func (l *Leaderboard) RecordHarvest(ctx context.Context, userID uuid.UUID, data HarvestEventData, at time.Time) error {
+	member := userID.String()
+
+	pipe := l.redis.Pipeline()
+	for _, window := range LeaderboardWindows {
+		period := window.Period(at)
+		pipe.ZIncrBy(ctx, leaderboardKey(window, period, BoardExperience), float64(data.ExperienceEarned), member)
+		pipe.ZIncrBy(ctx, leaderboardKey(window, period, BoardHarvests), 1, member)
+		pipe.ZIncrBy(ctx, leaderboardKey(window, period, PlantTypeBoard(data.PlantTypeID)), 1, member)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RecordRewards adds coins and experience earned outside of harvests, such as by
+// selling goods, to the leaderboards
+func (l *Leaderboard) RecordRewards(ctx context.Context, userID uuid.UUID, coins, experience int, at time.Time) error {
+	member := userID.String()
+
+	pipe := l.redis.Pipeline()
+	for _, window := range LeaderboardWindows {
+		period := window.Period(at)
+		if coins > 0 {
+			pipe.ZIncrBy(ctx, leaderboardKey(window, period, BoardCoins), float64(coins), member)
+		}
+		if experience > 0 {
+			pipe.ZIncrBy(ctx, leaderboardKey(window, period, BoardExperience), float64(experience), member)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Top returns a page of the current period of a leaderboard, best players first
+func (l *Leaderboard) Top(ctx context.Context, board LeaderboardBoard, window LeaderboardWindow, page, limit int) (*LeaderboardPage, error) {
+	period := window.Period(time.Now())
+	key := leaderboardKey(window, period, board)
+
+	start := int64((page - 1) * limit)
+	scores, err := l.redis.ZRevRangeWithScores(ctx, key, start, start+int64(limit)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read leaderboard: %w", err)
+	}
+	total, err := l.redis.ZCard(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count leaderboard: %w", err)
+	}
+
+	result := &LeaderboardPage{
+		Board:   board,
+		Window:  window,
+		Period:  period,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+		Entries: make([]LeaderboardEntry, 0, len(scores)),
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(scores))
+	for _, score := range scores {
+		if userID, err := uuid.Parse(score.Member); err == nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	var users []models.User
+	if err := l.db.DB.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to load players: %w", err)
+	}
+	byID := make(map[uuid.UUID]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	for i, score := range scores {
+		userID, err := uuid.Parse(score.Member)
+		if err != nil {
+			continue
+		}
+		user, ok := byID[userID]
+		if !ok {
+			// Deleted accounts keep their scores until the next rebuild
+			continue
+		}
+		result.Entries = append(result.Entries, LeaderboardEntry{
+			Rank:     start + int64(i) + 1,
+			UserID:   userID,
+			Username: user.Username,
+			Level:    user.Level,
+			Score:    int64(score.Score),
+		})
+	}
+
+	return result, nil
+}
+
+// RankOf looks up a player's standing in the current period of a leaderboard
+func (l *Leaderboard) RankOf(ctx context.Context, board LeaderboardBoard, window LeaderboardWindow, userID uuid.UUID) (*LeaderboardRank, error) {
+	period := window.Period(time.Now())
+	key := leaderboardKey(window, period, board)
+	member := userID.String()
+
+	pipe := l.redis.Pipeline()
+	rankCmd := pipe.ZRevRank(ctx, key, member)
+	scoreCmd := pipe.ZScore(ctx, key, member)
+	totalCmd := pipe.ZCard(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read rank: %w", err)
+	}
+
+	result := &LeaderboardRank{
+		Board:  board,
+		Window: window,
+		Period: period,
+		Total:  totalCmd.Val(),
+	}
+	if rank, err := rankCmd.Result(); err == nil {
+		rank++
+		result.Rank = &rank
+		result.Score = int64(scoreCmd.Val())
+	}
+
+	return result, nil
+}
+
+// harvestTotals is what a player harvested of one plant type
+type harvestTotals struct {
+	UserID      uuid.UUID
+	PlantTypeID uuid.UUID
+	Harvests    int64
+}
+
+// earningTotals is what a player earned in one currency
+type earningTotals struct {
+	UserID   uuid.UUID
+	Currency models.LedgerCurrency
+	Amount   int64
+}
+
+// Rebuild recomputes the current period of every leaderboard from Postgres, replacing
+// what is in Redis. Harvests are counted from the harvest history, coins and experience
+// earned from the ledger, and all-time experience from the players' totals.
+func (l *Leaderboard) Rebuild(ctx context.Context) error {
+	now := time.Now()
+
+	for _, window := range LeaderboardWindows {
+		boards := make(map[LeaderboardBoard]map[string]float64)
+		add := func(board LeaderboardBoard, userID uuid.UUID, score int64) {
+			if boards[board] == nil {
+				boards[board] = make(map[string]float64)
+			}
+			boards[board][userID.String()] += float64(score)
+		}
+		start := window.Start(now)
+
+		query := l.db.DB.Model(&models.HarvestRecord{}).
+			Select("user_id, plant_type_id, COUNT(*) AS harvests").
+			Group("user_id, plant_type_id")
+		if !start.IsZero() {
+			query = query.Where("harvested_at >= ?", start)
+		}
+
+		var harvests []harvestTotals
+		if err := query.Scan(&harvests).Error; err != nil {
+			return fmt.Errorf("failed to total %s harvests: %w", window, err)
+		}
+		for _, total := range harvests {
+			add(BoardHarvests, total.UserID, total.Harvests)
+			add(PlantTypeBoard(total.PlantTypeID), total.UserID, total.Harvests)
+		}
+
+		// Coins count what was earned selling goods; experience counts everything earned
+		query = l.db.DB.Model(&models.LedgerEntry{}).
+			Select("user_id, currency, SUM(amount) AS amount").
+			Where("(currency = ? AND reason = ?) OR (currency = ? AND reason <> ?)",
+				models.LedgerCoins, models.LedgerItemSale, models.LedgerExperience, models.LedgerOpeningBalance).
+			Group("user_id, currency")
+		if !start.IsZero() {
+			query = query.Where("created_at >= ?", start)
+		}
+
+		var earnings []earningTotals
+		if err := query.Scan(&earnings).Error; err != nil {
+			return fmt.Errorf("failed to total %s earnings: %w", window, err)
+		}
+		for _, total := range earnings {
+			switch {
+			case total.Currency == models.LedgerCoins:
+				add(BoardCoins, total.UserID, total.Amount)
+			case window != WindowAllTime:
+				add(BoardExperience, total.UserID, total.Amount)
+			}
+		}
+
+		if window == WindowAllTime {
+			var users []models.User
+			if err := l.db.DB.Where("experience > 0").Find(&users).Error; err != nil {
+				return fmt.Errorf("failed to load player experience: %w", err)
+			}
+			for _, user := range users {
+				add(BoardExperience, user.ID, int64(user.Experience))
+			}
+		}
+
+		if err := l.replacePeriod(ctx, window, window.Period(now), boards); err != nil {
+			return err
+		}
+	}
+
+	log.Println("Rebuilt leaderboards from the database")
+	return nil
+}
+
+// replacePeriod atomically swaps the boards of one period for freshly computed ones
+func (l *Leaderboard) replacePeriod(ctx context.Context, window LeaderboardWindow, period string, boards map[LeaderboardBoard]map[string]float64) error {
+	stale, err := l.keys(ctx, leaderboardKey(window, period, "*"))
+	if err != nil {
+		return err
+	}
+
+	pipe := l.redis.TxPipeline()
+	if len(stale) > 0 {
+		pipe.Del(ctx, stale...)
+	}
+	for board, scores := range boards {
+		members := make([]redis.Z, 0, len(scores))
+		for member, score := range scores {
+			members = append(members, redis.Z{Score: score, Member: member})
+		}
+		pipe.ZAdd(ctx, leaderboardKey(window, period, board), members...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to write %s leaderboards: %w", window, err)
+	}
+	return nil
+}
+
+// RebuildIfMissing rebuilds the leaderboards when Redis has lost them, such as after a flush
+func (l *Leaderboard) RebuildIfMissing(ctx context.Context) {
+	key := leaderboardKey(WindowAllTime, WindowAllTime.Period(time.Now()), BoardExperience)
+	exists, err := l.redis.Exists(ctx, key).Result()
+	if err != nil {
+		log.Printf("Failed to check leaderboards: %v", err)
+		return
+	}
+	if exists > 0 {
+		return
+	}
+
+	if err := l.Rebuild(ctx); err != nil {
+		log.Printf("Failed to rebuild leaderboards: %v", err)
+	}
+}
+
+// RunResets clears finished weekly and seasonal periods as each window rolls over,
+// until ctx is cancelled
+func (l *Leaderboard) RunResets(ctx context.Context) {
+	for {
+		l.resetFinishedPeriods(ctx)
+
+		now := time.Now()
+		next := WindowWeekly.End(now)
+		if seasonEnd := WindowSeasonal.End(now); seasonEnd.Before(next) {
+			next = seasonEnd
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// resetFinishedPeriods deletes every weekly and seasonal board that isn't for the current period
+func (l *Leaderboard) resetFinishedPeriods(ctx context.Context) {
+	now := time.Now()
+
+	for _, window := range []LeaderboardWindow{WindowWeekly, WindowSeasonal} {
+		prefix := leaderboardKeyPrefix + string(window) + ":"
+		current := prefix + window.Period(now) + ":"
+
+		keys, err := l.keys(ctx, prefix+"*")
+		if err != nil {
+			log.Printf("Failed to list %s leaderboards: %v", window, err)
+			continue
+		}
+
+		var finished []string
+		for _, key := range keys {
+			if !strings.HasPrefix(key, current) {
+				finished = append(finished, key)
+			}
+		}
+		if len(finished) == 0 {
+			continue
+		}
+
+		if err := l.redis.Del(ctx, finished...).Err(); err != nil {
+			log.Printf("Failed to reset %s leaderboards: %v", window, err)
+			continue
+		}
+		log.Printf("Reset %d %s leaderboards for period %s", len(finished), window, window.Period(now))
+	}
+}
+
+// keys lists the keys matching a pattern without blocking Redis
+func (l *Leaderboard) keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := l.redis.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", pattern, err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,278 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/my-garden/api/internal/database"
+	"github.com/my-garden/api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientCoins is returned when a balance change would leave a user with negative coins
+var ErrInsufficientCoins = errors.New("not enough coins")
+
+// reconcileInterval is how often the leader checks every balance against the ledger
+const reconcileInterval = time.Hour
+
+// LevelForExperience returns the level a player with the given experience has reached
+func LevelForExperience(experience int) int {
+	// Every 100 XP = 1 level
+	return (experience / 100) + 1
+}
+
+// BalanceChange is a change to a user's coins and experience and why it happened
+type BalanceChange struct {
+	UserID        uuid.UUID
+	Coins         int
+	Experience    int
+	Reason        models.LedgerReason
+	ReferenceType string
+	ReferenceID   uuid.UUID
+	Note          string
+}
+
+// Balance is a user's balances after a change
+type Balance struct {
+	Coins         int `json:"coins"`
+	Experience    int `json:"experience"`
+	Level         int `json:"level"`
+	PreviousLevel int `json:"previous_level"`
+}
+
+// LevelUp reports whether the change took the user to a new level
+func (b *Balance) LevelUp() bool {
+	return b.Level > b.PreviousLevel
+}
+
+// ApplyBalanceChange is the only way coins and experience change. It updates the user's
+// balances and appends the matching ledger entries, failing with ErrInsufficientCoins
+// rather than letting coins go negative. Pass the transaction the rest of the change
+// happens in, so the balances and ledger never disagree.
+func ApplyBalanceChange(tx *gorm.DB, change BalanceChange) (*Balance, error) {
+	var user models.User
+	result := tx.Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "coins"}, {Name: "experience"}, {Name: "level"}}}).
+		Where("id = ? AND coins + ? >= 0", change.UserID, change.Coins).
+		Updates(map[string]interface{}{
+			"coins":      gorm.Expr("coins + ?", change.Coins),
+			"experience": gorm.Expr("experience + ?", change.Experience),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInsufficientCoins
+	}
+
+	balance := &Balance{
+		Coins:         user.Coins,
+		Experience:    user.Experience,
+		Level:         user.Level,
+		PreviousLevel: user.Level,
+	}
+	if level := LevelForExperience(user.Experience); level > user.Level {
+		if err := tx.Model(&models.User{}).Where("id = ?", change.UserID).UpdateColumn("level", level).Error; err != nil {
+			return nil, err
+		}
+		balance.Level = level
+	}
+
+	var entries []models.LedgerEntry
+	if change.Coins != 0 {
+		entries = append(entries, change.entry(models.LedgerCoins, change.Coins, balance.Coins))
+	}
+	if change.Experience != 0 {
+		entries = append(entries, change.entry(models.LedgerExperience, change.Experience, balance.Experience))
+	}
+	if len(entries) > 0 {
+		if err := tx.Create(&entries).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return balance, nil
+}
+
+func (c BalanceChange) entry(currency models.LedgerCurrency, amount, balanceAfter int) models.LedgerEntry {
+	entry := models.LedgerEntry{
+		UserID:        c.UserID,
+		Currency:      currency,
+		Amount:        amount,
+		BalanceAfter:  balanceAfter,
+		Reason:        c.Reason,
+		ReferenceType: c.ReferenceType,
+		Note:          c.Note,
+	}
+	if c.ReferenceID != uuid.Nil {
+		referenceID := c.ReferenceID
+		entry.ReferenceID = &referenceID
+	}
+	return entry
+}
+
+// OpenLedger records a new user's starting balances
+func OpenLedger(tx *gorm.DB, user *models.User) error {
+	change := BalanceChange{UserID: user.ID, Reason: models.LedgerOpeningBalance}
+
+	var entries []models.LedgerEntry
+	if user.Coins != 0 {
+		entries = append(entries, change.entry(models.LedgerCoins, user.Coins, user.Coins))
+	}
+	if user.Experience != 0 {
+		entries = append(entries, change.entry(models.LedgerExperience, user.Experience, user.Experience))
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return tx.Create(&entries).Error
+}
+
+// LedgerPage is a page of a user's ledger, newest entries first
+type LedgerPage struct {
+	Entries []models.LedgerEntry `json:"entries"`
+	Page    int                  `json:"page"`
+	Limit   int                  `json:"limit"`
+	Total   int64                `json:"total"`
+}
+
+// ReconciledBalance compares a balance with the sum of its ledger entries
+type ReconciledBalance struct {
+	Balance     int `json:"balance"`
+	LedgerTotal int `json:"ledger_total"`
+	Difference  int `json:"difference"`
+}
+
+// Reconciliation reports whether a user's balances match their ledger
+type Reconciliation struct {
+	Coins      ReconciledBalance `json:"coins"`
+	Experience ReconciledBalance `json:"experience"`
+	Reconciled bool              `json:"reconciled"`
+	CheckedAt  time.Time         `json:"checked_at"`
+}
+
+// ledgerTotals is a user's balances alongside their ledger sums
+type ledgerTotals struct {
+	UserID           uuid.UUID
+	Coins            int
+	Experience       int
+	LedgerCoins      int
+	LedgerExperience int
+}
+
+func (t ledgerTotals) reconciliation() *Reconciliation {
+	r := &Reconciliation{
+		Coins: ReconciledBalance{
+			Balance:     t.Coins,
+			LedgerTotal: t.LedgerCoins,
+			Difference:  t.Coins - t.LedgerCoins,
+		},
+		Experience: ReconciledBalance{
+			Balance:     t.Experience,
+			LedgerTotal: t.LedgerExperience,
+			Difference:  t.Experience - t.LedgerExperience,
+		},
+		CheckedAt: time.Now(),
+	}
+	r.Reconciled = r.Coins.Difference == 0 && r.Experience.Difference == 0
+	return r
+}
+
+// Ledger reads the coin and experience ledger and checks it against user balances
+type Ledger struct {
+	db *database.Database
+}
+
+func NewLedger(db *database.Database) *Ledger {
+	return &Ledger{db: db}
+}
+
+// totals sums the ledger of every user, or of a single user when userID is set
+func (l *Ledger) totals(userID uuid.UUID) *gorm.DB {
+	query := l.db.DB.Table("users").
+		Select("users.id AS user_id, users.coins, users.experience, " +
+			"COALESCE(SUM(ledger_entries.amount) FILTER (WHERE ledger_entries.currency = 'coins'), 0) AS ledger_coins, " +
+			"COALESCE(SUM(ledger_entries.amount) FILTER (WHERE ledger_entries.currency = 'experience'), 0) AS ledger_experience").
+		Joins("LEFT JOIN ledger_entries ON ledger_entries.user_id = users.id").
+		Group("users.id, users.coins, users.experience")
+	if userID != uuid.Nil {
+		query = query.Where("users.id = ?", userID)
+	}
+	return query
+}
+
+// Entries returns a page of a user's ledger, optionally for a single currency
+func (l *Ledger) Entries(userID uuid.UUID, currency models.LedgerCurrency, page, limit int) (*LedgerPage, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("user_id = ?", userID)
+		if currency != "" {
+			db = db.Where("currency = ?", currency)
+		}
+		return db
+	}
+
+	result := &LedgerPage{Page: page, Limit: limit, Entries: []models.LedgerEntry{}}
+	if err := l.db.DB.Model(&models.LedgerEntry{}).Scopes(filter).Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+	if err := l.db.DB.Scopes(filter).Order("created_at DESC, id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&result.Entries).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Reconcile checks that a user's coins and experience equal the sum of their ledger
+func (l *Ledger) Reconcile(userID uuid.UUID) (*Reconciliation, error) {
+	var totals ledgerTotals
+	if err := l.totals(userID).Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+	return totals.reconciliation(), nil
+}
+
+// ReconcileAll checks every user's balances against the ledger, logging any that
+// disagree. It returns how many users are out of balance.
+func (l *Ledger) ReconcileAll() (int, error) {
+	var mismatched []ledgerTotals
+	if err := l.totals(uuid.Nil).
+		Having("users.coins <> COALESCE(SUM(ledger_entries.amount) FILTER (WHERE ledger_entries.currency = 'coins'), 0) " +
+			"OR users.experience <> COALESCE(SUM(ledger_entries.amount) FILTER (WHERE ledger_entries.currency = 'experience'), 0)").
+		Scan(&mismatched).Error; err != nil {
+		return 0, err
+	}
+
+	for _, totals := range mismatched {
+		r := totals.reconciliation()
+		log.Printf("Ledger mismatch for user %s: coins %d vs ledger %d, experience %d vs ledger %d",
+			totals.UserID, r.Coins.Balance, r.Coins.LedgerTotal, r.Experience.Balance, r.Experience.LedgerTotal)
+	}
+	return len(mismatched), nil
+}
+
+// RunReconciliation periodically checks every balance against the ledger until ctx is cancelled
+func (l *Ledger) RunReconciliation(ctx context.Context) {
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		mismatched, err := l.ReconcileAll()
+		if err != nil {
+			log.Printf("Failed to reconcile ledger: %v", err)
+		} else if mismatched == 0 {
+			log.Println("Ledger reconciled with every user balance")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
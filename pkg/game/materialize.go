@@ -0,0 +1,324 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/my-garden/api/internal/models"
+	"gorm.io/gorm"
+)
+
+// sweepBatchSize bounds how many plants the background sweep loads at once
+const sweepBatchSize = 500
+
+// weatherSpan is a stretch of time during which one Weather row applied
+type weatherSpan struct {
+	Weather models.Weather
+	Start   time.Time
+	End     time.Time
+}
+
+// defaultWeather applies before the first Weather row was ever generated
+var defaultWeather = models.Weather{
+	Condition:            models.WeatherCloudy,
+	Temperature:          15.0,
+	Humidity:             50,
+	GrowthMultiplier:     1.0,
+	WaterEvaporationRate: 1.0,
+}
+
+// loadWeatherSpans returns the weather that applied over [from, to]. Each row applies
+// from its creation until the next row replaces it.
+func (g *GameEngine) loadWeatherSpans(from, to time.Time) ([]weatherSpan, error) {
+	var rows []models.Weather
+
+	// Weather in effect when the window opens
+	var opening models.Weather
+	err := g.db.DB.Where("created_at <= ?", from).Order("created_at DESC").First(&opening).Error
+	switch {
+	case err == nil:
+		rows = append(rows, opening)
+	case err != gorm.ErrRecordNotFound:
+		return nil, fmt.Errorf("failed to load weather at %s: %w", from, err)
+	}
+
+	var changes []models.Weather
+	if err := g.db.DB.Where("created_at > ? AND created_at < ?", from, to).Order("created_at ASC").Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load weather history: %w", err)
+	}
+	rows = append(rows, changes...)
+
+	var spans []weatherSpan
+	cursor := from
+	for i, row := range rows {
+		if row.CreatedAt.After(cursor) {
+			if i == 0 {
+				// No weather had been generated yet at the start of the window
+				spans = append(spans, weatherSpan{Weather: defaultWeather, Start: cursor, End: row.CreatedAt})
+			}
+			cursor = row.CreatedAt
+		}
+
+		end := to
+		if i+1 < len(rows) {
+			end = rows[i+1].CreatedAt
+		}
+		spans = append(spans, weatherSpan{Weather: row, Start: cursor, End: end})
+		cursor = end
+	}
+	if len(spans) == 0 {
+		spans = append(spans, weatherSpan{Weather: defaultWeather, Start: from, End: to})
+	}
+
+	return spans, nil
+}
+
+// lastEvaluated returns when the simulation last caught a plant up
+func lastEvaluated(plant *models.Plant) time.Time {
+	if plant.LastEvaluatedAt.IsZero() {
+		// Rows created before lazy evaluation existed
+		return plant.UpdatedAt
+	}
+	return plant.LastEvaluatedAt
+}
+
+// simulate catches a plant in the given garden up to now using the given weather spans
+func (g *GameEngine) simulate(plant *models.Plant, garden *models.Garden, spans []weatherSpan, now time.Time) {
+	from := lastEvaluated(plant)
+	state := NewGrowthState(plant)
+	model := g.growthModelFor(&plant.PlantType)
+	var witheredAt, ripenedAt *time.Time
+
+	for _, span := range spans {
+		if !state.Growing() {
+			break
+		}
+
+		start, end := span.Start, span.End
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(now) {
+			end = now
+		}
+
+		// Split the span where the season changes
+		for end.After(start) && state.Growing() {
+			segmentEnd := nextSeasonStart(start)
+			if segmentEnd.After(end) {
+				segmentEnd = end
+			}
+
+			env := gardenEnvironment(garden, &span.Weather, models.GetSeason(start))
+			state = model.Advance(state, &plant.PlantType, env, segmentEnd.Sub(start))
+			if !state.Growing() {
+				// The plant stopped growing partway through the segment
+				stoppedAt := segmentEnd.Add(-state.Unused)
+				if state.Stage == models.PlantStageWithered {
+					witheredAt = &stoppedAt
+				} else {
+					ripenedAt = &stoppedAt
+				}
+			}
+			start = segmentEnd
+		}
+	}
+
+	state.Apply(plant)
+	plant.LastEvaluatedAt = now
+	if witheredAt != nil {
+		plant.WitheredAt = witheredAt
+		plant.DeathReason = state.Cause
+	}
+	if ripenedAt != nil {
+		plant.RipenedAt = ripenedAt
+	}
+	if plant.Stage == models.PlantStageHarvestable && plant.RipenedAt == nil {
+		// Plants that ripened before spoilage was tracked start their window now
+		plant.RipenedAt = &now
+	}
+	spoil(plant, now)
+
+	plant.Condition = nil
+	if state.Growing() && len(spans) > 0 {
+		current := spans[len(spans)-1]
+		plant.Condition = AssessPlant(plant, gardenEnvironment(garden, &current.Weather, models.GetSeason(now)))
+	}
+	plant.Ripeness = assessRipeness(plant, now)
+	plant.Revival, _ = Revival(plant, now)
+}
+
+// gardenEnvironment describes the conditions plants in a garden experience
+func gardenEnvironment(garden *models.Garden, weather *models.Weather, season models.Season) Environment {
+	return Environment{
+		Weather:    weather,
+		Season:     season,
+		Sprinkler:  garden.HasSprinkler,
+		Greenhouse: garden.HasGreenhouse,
+	}
+}
+
+// materialize brings plants up to date, persists them and publishes what changed.
+// gardenOf resolves the garden a plant grows in.
+func (g *GameEngine) materialize(plants []*models.Plant, gardenOf func(*models.Plant) *models.Garden) error {
+	if len(plants) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	from := now
+	for _, plant := range plants {
+		if evaluated := lastEvaluated(plant); evaluated.Before(from) {
+			from = evaluated
+		}
+	}
+
+	spans, err := g.loadWeatherSpans(from, now)
+	if err != nil {
+		return err
+	}
+
+	for _, plant := range plants {
+		if plant.PlantType.ID == uuid.Nil {
+			if err := g.db.DB.First(&plant.PlantType, plant.PlantTypeID).Error; err != nil {
+				return fmt.Errorf("failed to load plant type for plant %s: %w", plant.ID, err)
+			}
+		}
+
+		garden := gardenOf(plant)
+		previous := *plant
+		g.simulate(plant, garden, spans, now)
+
+		// Guard against a concurrent evaluation having already moved the plant on
+		result := g.db.DB.Model(&models.Plant{}).
+			Where("id = ? AND last_evaluated_at = ?", plant.ID, previous.LastEvaluatedAt).
+			Updates(map[string]interface{}{
+				"stage":                    plant.Stage,
+				"growth_progress":          plant.GrowthProgress,
+				"water_level":              plant.WaterLevel,
+				"fertilizer_level":         plant.FertilizerLevel,
+				"health":                   plant.Health,
+				"exact_health":             plant.Exact.Health,
+				"exact_water":              plant.Exact.Water,
+				"exact_fertilizer":         plant.Exact.Fertilizer,
+				"care_minutes":             plant.Care.Minutes,
+				"care_health_minutes":      plant.Care.HealthMinutes,
+				"care_ideal_water_minutes": plant.Care.IdealWaterMinutes,
+				"care_fed_minutes":         plant.Care.FedMinutes,
+				"withered_at":              plant.WitheredAt,
+				"ripened_at":               plant.RipenedAt,
+				"death_reason":             plant.DeathReason,
+				"last_evaluated_at":        plant.LastEvaluatedAt,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to save plant %s: %w", plant.ID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			// Somebody else got there first; reload their result
+			if err := g.db.DB.First(plant, plant.ID).Error; err != nil {
+				return fmt.Errorf("failed to reload plant %s: %w", plant.ID, err)
+			}
+			continue
+		}
+
+		if event, changed := growthEvent(garden.UserID, &previous, plant); changed {
+			g.PublishEvent(event)
+		}
+	}
+
+	return nil
+}
+
+// MaterializeGarden brings every plant in a garden up to date. The garden's plants
+// must already be loaded.
+func (g *GameEngine) MaterializeGarden(garden *models.Garden) error {
+	plants := make([]*models.Plant, len(garden.Plants))
+	for i := range garden.Plants {
+		plants[i] = &garden.Plants[i]
+	}
+
+	return g.materialize(plants, func(*models.Plant) *models.Garden { return garden })
+}
+
+// MaterializePlant brings a single plant up to date before it is read or changed
+func (g *GameEngine) MaterializePlant(plant *models.Plant) error {
+	garden, err := g.gardenOf(plant)
+	if err != nil {
+		return err
+	}
+
+	return g.materialize([]*models.Plant{plant}, func(*models.Plant) *models.Garden { return garden })
+}
+
+// gardenOf loads the garden a plant grows in
+func (g *GameEngine) gardenOf(plant *models.Plant) (*models.Garden, error) {
+	var garden models.Garden
+	if err := g.db.DB.First(&garden, plant.GardenID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load garden for plant %s: %w", plant.ID, err)
+	}
+	return &garden, nil
+}
+
+// RefreshCondition reassesses a plant against the current weather and season, for
+// use after a player action changed it
+func (g *GameEngine) RefreshCondition(plant *models.Plant) {
+	plant.Condition = nil
+	if !NewGrowthState(plant).Growing() {
+		return
+	}
+
+	garden, err := g.gardenOf(plant)
+	if err != nil {
+		log.Printf("Failed to assess plant %s: %v", plant.ID, err)
+		return
+	}
+
+	now := time.Now()
+	spans, err := g.loadWeatherSpans(now, now)
+	if err != nil {
+		log.Printf("Failed to assess plant %s: %v", plant.ID, err)
+		return
+	}
+	current := spans[len(spans)-1]
+	plant.Condition = AssessPlant(plant, gardenEnvironment(garden, &current.Weather, models.GetSeason(now)))
+}
+
+// sweepPlants catches up plants nobody has looked at for a while, so stage changes
+// are noticed even in gardens that are never opened. Ripe plants are only swept once
+// they are due to spoil. It returns how many plants were processed and how many of
+// those failed.
+func (g *GameEngine) sweepPlants() (processed, failed int) {
+	now := time.Now()
+	cutoff := now.Add(-g.config.Game.PlantGrowthInterval)
+
+	var plants []models.Plant
+	result := g.db.DB.Preload("PlantType").Preload("Garden").
+		Joins("JOIN plant_types ON plant_types.id = plants.plant_type_id").
+		Where("plants.last_evaluated_at < ?", cutoff).
+		Where("plants.stage NOT IN ? OR (plants.stage = ? AND plant_types.ripe_window > 0 AND "+
+			"(plants.ripened_at IS NULL OR plants.ripened_at + (plant_types.ripe_window + GREATEST(plant_types.spoil_time, 0)) * INTERVAL '1 minute' <= ?))",
+			[]models.PlantStage{models.PlantStageHarvestable, models.PlantStageWithered}, models.PlantStageHarvestable, now).
+		FindInBatches(&plants, sweepBatchSize, func(tx *gorm.DB, batch int) error {
+			batchPlants := make([]*models.Plant, len(plants))
+			for i := range plants {
+				batchPlants[i] = &plants[i]
+			}
+
+			processed += len(plants)
+			if err := g.materialize(batchPlants, func(plant *models.Plant) *models.Garden { return &plant.Garden }); err != nil {
+				// Keep going so one bad plant doesn't stall every other garden
+				log.Printf("Failed to sweep batch %d: %v", batch, err)
+				failed += len(plants)
+			}
+			return nil
+		})
+	if result.Error != nil {
+		log.Printf("Failed to sweep plants: %v", result.Error)
+		failed++
+	}
+
+	log.Printf("Swept %d plants", processed)
+	return processed, failed
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/my-garden/api/internal/models"
+)
+
+// Preference effects for plant types that care about the season or weather.
+// Plant types that accept any season or weather are unaffected.
+var (
+	seasonMatch     = careBand{Growth: 1.15}
+	seasonMismatch  = careBand{Growth: 0.7, Health: -0.5, Cause: models.DeathPests}
+	weatherMatch    = careBand{Growth: 1.1}
+	weatherMismatch = careBand{Growth: 0.9}
+)
+
+// Status cutoffs for the combined effect of everything acting on a plant
+const (
+	thrivingGrowthAbove   = 1.3
+	strugglingGrowthBelow = 0.8
+)
+
+// seasonPreference returns how a season affects a plant type
+func seasonPreference(plantType *models.PlantType, season models.Season) careBand {
+	switch {
+	case plantType.Season == "" || plantType.Season == "all":
+		return careBand{Growth: 1.0, Reason: "grows in any season"}
+	case plantType.InSeason(season):
+		band := seasonMatch
+		band.Reason = fmt.Sprintf("in season (%s)", season)
+		return band
+	default:
+		band := seasonMismatch
+		band.Reason = fmt.Sprintf("out of season: prefers %s, it is %s, which leaves it open to pests", plantType.Season, season)
+		return band
+	}
+}
+
+// weatherPreference returns how a weather condition affects a plant type
+func weatherPreference(plantType *models.PlantType, condition models.WeatherCondition) careBand {
+	switch {
+	case plantType.Weather == "" || plantType.Weather == "all":
+		return careBand{Growth: 1.0, Reason: "grows in any weather"}
+	case plantType.PrefersWeather(condition):
+		band := weatherMatch
+		band.Reason = fmt.Sprintf("enjoying the %s weather", condition)
+		return band
+	default:
+		band := weatherMismatch
+		band.Reason = fmt.Sprintf("prefers %s weather, it is %s", plantType.Weather, condition)
+		return band
+	}
+}
+
+// nextSeasonStart returns when the season following t's season begins
+func nextSeasonStart(t time.Time) time.Time {
+	month := t.Month()
+	// Seasons start in March, June, September and December
+	next := month + 3 - (month % 3)
+	year := t.Year()
+	if next > 12 {
+		next -= 12
+		year++
+	}
+	return time.Date(year, next, 1, 0, 0, 0, 0, t.Location())
+}
+
+// AssessPlant explains how the given conditions affect a plant
+func AssessPlant(plant *models.Plant, env Environment) *models.PlantCondition {
+	plantType := &plant.PlantType
+	condition := &models.PlantCondition{GrowthMultiplier: 1.0}
+
+	add := func(factor string, band careBand) {
+		effect := "neutral"
+		switch {
+		case band.Health < 0 || band.Growth < 1.0:
+			effect = "penalty"
+		case band.Health > 0 || band.Growth > 1.0:
+			effect = "bonus"
+		}
+
+		condition.Factors = append(condition.Factors, models.ConditionFactor{
+			Factor:           factor,
+			Effect:           effect,
+			Reason:           band.Reason,
+			GrowthMultiplier: band.Growth,
+			HealthPerTick:    band.Health,
+		})
+		condition.GrowthMultiplier *= band.Growth
+		condition.HealthPerTick += band.Health
+	}
+
+	add("climate", climateEffect(env))
+	add("temperature", temperatureEffect(plantType, env))
+	if event, ok := eventEffect(env); ok {
+		add("event", event)
+	}
+	add("season", seasonPreference(plantType, env.Season))
+	add("weather", weatherPreference(plantType, env.Weather.Condition))
+	_, water, fertilizer := plant.Levels()
+	add("water", waterCare(water, plantType.WaterNeeds))
+	add("fertilizer", fertilizerCare(fertilizer, plantType.FertilizerNeeds))
+
+	switch {
+	case condition.HealthPerTick < 0 || condition.GrowthMultiplier < strugglingGrowthBelow:
+		condition.Status = models.PlantStatusStruggling
+	case condition.GrowthMultiplier > thrivingGrowthAbove:
+		condition.Status = models.PlantStatusThriving
+	default:
+		condition.Status = models.PlantStatusHealthy
+	}
+
+	return condition
+}
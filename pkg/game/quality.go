@@ -0,0 +1,144 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/my-garden/api/internal/models"
+)
+
+// Weights of the parts of a quality score, adding up to 100
+const (
+	qualityHealthWeight     = 50.0 // average health while growing
+	qualityWaterWeight      = 25.0 // share of time watered just right
+	qualityFertilizerWeight = 15.0 // share of time well fed
+	qualityLuckWeight       = 10.0 // weather luck on the day of the harvest
+)
+
+// Lowest score for each grade above poor
+const (
+	qualityNormalFrom    = 40.0
+	qualityGoodFrom      = 65.0
+	qualityExcellentFrom = 85.0
+)
+
+// qualityReward scales the rewards of a harvest of some quality
+type qualityReward struct {
+	Yield      float64
+	Price      float64
+	Experience float64
+}
+
+var qualityRewards = map[models.HarvestQuality]qualityReward{
+	models.QualityPoor:      {Yield: 0.5, Price: 0.6, Experience: 0.5},
+	models.QualityNormal:    {Yield: 1.0, Price: 1.0, Experience: 1.0},
+	models.QualityGood:      {Yield: 1.25, Price: 1.2, Experience: 1.25},
+	models.QualityExcellent: {Yield: 1.5, Price: 1.5, Experience: 1.5},
+}
+
+// recordCare adds a step of simulated growth to a plant's care history
+func recordCare(care *models.CareHistory, minutes, healthBefore, healthAfter float64, water, fertilizer careBand, plantType *models.PlantType) {
+	care.Minutes += minutes
+	// Health changes linearly within a step
+	care.HealthMinutes += (healthBefore + healthAfter) / 2 * minutes
+	if water == waterIdeal {
+		care.IdealWaterMinutes += minutes
+	}
+	if wellFed(fertilizer, plantType) {
+		care.FedMinutes += minutes
+	}
+}
+
+// wellFed reports whether a fertilizer band counts as good care. Species that don't
+// need feeding are well fed as long as they aren't burned.
+func wellFed(fertilizer careBand, plantType *models.PlantType) bool {
+	return fertilizer == fertilizerFed || (plantType.FertilizerNeeds == 0 && fertilizer == fertilizerOK)
+}
+
+// HarvestGrade is the quality a harvest was graded at
+type HarvestGrade struct {
+	Quality models.HarvestQuality `json:"quality"`
+	Score   float64               `json:"score"` // 0-100
+}
+
+// GradeHarvest grades a plant's harvest from its average health while growing, how
+// much of that time its water and fertilizer were right for the species, and a roll
+// of luck. Plants that enjoy the current weather get two rolls and keep the better.
+// Weather may be nil if none is known.
+func GradeHarvest(plant *models.Plant, weather *models.Weather) HarvestGrade {
+	health, water, fed := careAverages(plant)
+
+	luck := rand.Float64()
+	if weather != nil && plant.PlantType.PrefersWeather(weather.Condition) {
+		luck = math.Max(luck, rand.Float64())
+	}
+
+	score := health/100*qualityHealthWeight +
+		water*qualityWaterWeight +
+		fed*qualityFertilizerWeight +
+		luck*qualityLuckWeight
+	score = math.Round(score*10) / 10
+
+	return HarvestGrade{Quality: qualityForScore(score), Score: score}
+}
+
+// careAverages returns a plant's average health and the share of its growing time it
+// was watered just right and well fed
+func careAverages(plant *models.Plant) (health, water, fed float64) {
+	care := plant.Care
+	if care.Minutes <= 0 {
+		// Plants that finished growing before care was tracked are judged as they are now
+		health, waterLevel, fertilizerLevel := plant.Levels()
+		if waterCare(waterLevel, plant.PlantType.WaterNeeds) == waterIdeal {
+			water = 1
+		}
+		if wellFed(fertilizerCare(fertilizerLevel, plant.PlantType.FertilizerNeeds), &plant.PlantType) {
+			fed = 1
+		}
+		return health, water, fed
+	}
+
+	return care.HealthMinutes / care.Minutes,
+		math.Min(1, care.IdealWaterMinutes/care.Minutes),
+		math.Min(1, care.FedMinutes/care.Minutes)
+}
+
+// qualityForScore returns the grade a quality score earns
+func qualityForScore(score float64) models.HarvestQuality {
+	switch {
+	case score >= qualityExcellentFrom:
+		return models.QualityExcellent
+	case score >= qualityGoodFrom:
+		return models.QualityGood
+	case score >= qualityNormalFrom:
+		return models.QualityNormal
+	default:
+		return models.QualityPoor
+	}
+}
+
+// rewardFor returns how a quality scales rewards, treating unknown grades as normal
+func rewardFor(quality models.HarvestQuality) qualityReward {
+	if reward, ok := qualityRewards[quality]; ok {
+		return reward
+	}
+	return qualityRewards[models.QualityNormal]
+}
+
+// scaleReward scales a base reward, never taking a positive reward below one
+func scaleReward(base int, multiplier float64) int {
+	if base <= 0 {
+		return base
+	}
+	return int(math.Max(1, math.Round(float64(base)*multiplier)))
+}
+
+// HarvestYield is how many items a harvest of the given quality produces
+func HarvestYield(plantType *models.PlantType, quality models.HarvestQuality) int {
+	return scaleReward(plantType.Yield, rewardFor(quality).Yield)
+}
+
+// HarvestExperience is the experience a harvest of the given quality is worth
+func HarvestExperience(plantType *models.PlantType, quality models.HarvestQuality) int {
+	return scaleReward(plantType.ExperienceValue, rewardFor(quality).Experience)
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/my-garden/api/internal/models"
+)
+
+const (
+	// ReviveGracePeriod is how long after dying a plant can still be revived. It is
+	// shorter than compostDelay so the composter never takes a plant that could be saved.
+	ReviveGracePeriod = 30 * time.Minute
+
+	// ReviveItems is how many harvested goods of its own type reviving a plant takes
+	ReviveItems = 2
+
+	// reviveSeedPrices is the coin cost of reviving a plant, in seeds of its type
+	reviveSeedPrices = 3
+
+	// reviveHealth is the health a revived plant comes back with
+	reviveHealth = 50.0
+)
+
+// Ways to pay for reviving a plant
+const (
+	RevivePayCoins = "coins"
+	RevivePayItems = "items"
+)
+
+var (
+	// ErrNotWithered is returned when reviving a plant that is still alive
+	ErrNotWithered = errors.New("plant is not withered")
+	// ErrRevivalExpired is returned when a plant has been dead longer than ReviveGracePeriod
+	ErrRevivalExpired = errors.New("plant has been dead too long to revive")
+)
+
+// ReviveCoins is the coin cost of reviving a plant of the given type
+func ReviveCoins(plantType *models.PlantType) int {
+	return plantType.SeedPrice * reviveSeedPrices
+}
+
+// Revival returns what it takes to revive a withered plant, or an error explaining
+// why it can't be. Harvested and spoiled plants are gone for good.
+func Revival(plant *models.Plant, now time.Time) (*models.PlantRevival, error) {
+	if plant.Stage != models.PlantStageWithered {
+		return nil, ErrNotWithered
+	}
+
+	switch plant.DeathReason {
+	case models.DeathHarvested, models.DeathSpoiled:
+		return nil, fmt.Errorf("%s plants can't be revived", plant.DeathReason)
+	case "":
+		// Plants that died before death reasons were recorded
+		return nil, ErrRevivalExpired
+	}
+
+	if plant.WitheredAt == nil || now.Sub(*plant.WitheredAt) > ReviveGracePeriod {
+		return nil, ErrRevivalExpired
+	}
+
+	return &models.PlantRevival{
+		RevivableUntil: plant.WitheredAt.Add(ReviveGracePeriod),
+		Coins:          ReviveCoins(&plant.PlantType),
+		Items:          ReviveItems,
+	}, nil
+}
+
+// RevivePlant brings a withered plant back to life in the stage its growth had
+// reached, with enough water and no more fertilizer than it needs, so whatever killed
+// it doesn't immediately do so again. Check Revival first.
+func (g *GameEngine) RevivePlant(plant *models.Plant, now time.Time) {
+	plantType := &plant.PlantType
+
+	stage := DefaultStageThresholds.Stage(plant.GrowthProgress + growthEpsilon)
+	if model, ok := g.growthModelFor(plantType).(*StagedGrowthModel); ok {
+		stage = model.Stages.Stage(plant.GrowthProgress + growthEpsilon)
+	}
+	if stage == models.PlantStageHarvestable {
+		// Only ripe plants can be harvestable, and those spoil rather than die
+		stage = models.PlantStageMature
+	}
+
+	_, water, fertilizer := plant.Levels()
+	if waterCare(water, plantType.WaterNeeds) != waterIdeal {
+		water = float64(plantType.WaterNeeds)
+	}
+	if fertilizerCare(fertilizer, plantType.FertilizerNeeds) == fertilizerBurn {
+		fertilizer = float64(plantType.FertilizerNeeds)
+	}
+	plant.Stage = stage
+	plant.SetLevels(reviveHealth, water, fertilizer)
+	plant.WitheredAt = nil
+	plant.DeathReason = ""
+	plant.RevivedAt = &now
+	plant.LastEvaluatedAt = now
+	plant.Revival = nil
+}
@@ -0,0 +1,81 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/my-garden/api/internal/models"
+)
+
+// spoiledFreshness is the share of its value a harvest keeps just before the plant rots
+const spoiledFreshness = 0.25
+
+// spoilWindow returns when a ripe plant stops being fresh and when it rots. It reports
+// false for plants that aren't ripe or never spoil.
+func spoilWindow(plant *models.Plant) (freshUntil, spoilsAt time.Time, ok bool) {
+	if plant.Stage != models.PlantStageHarvestable || plant.RipenedAt == nil || plant.PlantType.RipeWindow <= 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	freshUntil = plant.RipenedAt.Add(time.Duration(plant.PlantType.RipeWindow) * time.Minute)
+	spoilTime := plant.PlantType.SpoilTime
+	if spoilTime < 0 {
+		spoilTime = 0
+	}
+	spoilsAt = freshUntil.Add(time.Duration(spoilTime) * time.Minute)
+	return freshUntil, spoilsAt, true
+}
+
+// Freshness returns the share of its value a plant's harvest is worth at the given
+// time: all of it during the ripe window, then falling steadily until the plant rots
+func Freshness(plant *models.Plant, at time.Time) float64 {
+	freshUntil, spoilsAt, ok := spoilWindow(plant)
+	if !ok || !at.After(freshUntil) {
+		return 1
+	}
+	if !at.Before(spoilsAt) {
+		return spoiledFreshness
+	}
+
+	overripe := at.Sub(freshUntil).Seconds() / spoilsAt.Sub(freshUntil).Seconds()
+	return math.Round((1-overripe*(1-spoiledFreshness))*100) / 100
+}
+
+// FreshYield is how many of a harvest's items are still good at the given freshness
+func FreshYield(items int, freshness float64) int {
+	return scaleReward(items, freshness)
+}
+
+// assessRipeness counts down to a ripe plant spoiling, or returns nil if it won't
+func assessRipeness(plant *models.Plant, now time.Time) *models.PlantRipeness {
+	freshUntil, spoilsAt, ok := spoilWindow(plant)
+	if !ok {
+		return nil
+	}
+
+	ripeness := &models.PlantRipeness{
+		Status:              models.RipenessFresh,
+		Freshness:           Freshness(plant, now),
+		FreshUntil:          freshUntil,
+		SpoilsAt:            spoilsAt,
+		SecondsUntilSpoiled: int64(math.Max(0, spoilsAt.Sub(now).Seconds())),
+	}
+	if now.After(freshUntil) {
+		ripeness.Status = models.RipenessOverripe
+	}
+	return ripeness
+}
+
+// spoil rots a ripe plant that has been left past its spoil time. It reports whether
+// the plant spoiled.
+func spoil(plant *models.Plant, now time.Time) bool {
+	_, spoilsAt, ok := spoilWindow(plant)
+	if !ok || now.Before(spoilsAt) {
+		return false
+	}
+
+	plant.Stage = models.PlantStageWithered
+	plant.WitheredAt = &spoilsAt
+	plant.DeathReason = models.DeathSpoiled
+	return true
+}
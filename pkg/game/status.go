@@ -0,0 +1,156 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/my-garden/api/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// tickStatsKey holds the leader's latest tick report so every replica can serve it
+const tickStatsKey = "game:tick"
+
+// TickStats reports how the background simulation is doing
+type TickStats struct {
+	LeaderID               string    `json:"leader_id"`
+	LastTickAt             time.Time `json:"last_tick_at"`
+	LastTickDurationMs     int64     `json:"last_tick_duration_ms"`
+	PlantsProcessed        int       `json:"plants_processed"`
+	PlantsComposted        int       `json:"plants_composted"`
+	Failures               int       `json:"failures"`
+	ConsecutiveFailedTicks int       `json:"consecutive_failed_ticks"`
+	TotalTicks             int64     `json:"total_ticks"`
+	TotalFailures          int64     `json:"total_failures"`
+	NextTickAt             time.Time `json:"next_tick_at"`
+}
+
+// record folds the outcome of a tick into the stats
+func (s *TickStats) record(started time.Time, duration time.Duration, processed, composted, failures int) {
+	s.LastTickAt = started
+	s.LastTickDurationMs = duration.Milliseconds()
+	s.PlantsProcessed = processed
+	s.PlantsComposted = composted
+	s.Failures = failures
+	s.TotalTicks++
+	s.TotalFailures += int64(failures)
+
+	if failures > 0 {
+		s.ConsecutiveFailedTicks++
+	} else {
+		s.ConsecutiveFailedTicks = 0
+	}
+}
+
+// loadTickStats reads the last published tick report, so counters carry over when
+// leadership moves to another replica
+func (g *GameEngine) loadTickStats(ctx context.Context) *TickStats {
+	stats := &TickStats{}
+
+	payload, err := g.redis.Get(ctx, tickStatsKey).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			log.Printf("Failed to load tick stats: %v", err)
+		}
+		return stats
+	}
+	if err := json.Unmarshal(payload, stats); err != nil {
+		log.Printf("Discarding malformed tick stats: %v", err)
+		return &TickStats{}
+	}
+	return stats
+}
+
+func (g *GameEngine) saveTickStats(ctx context.Context, stats *TickStats) {
+	payload, err := json.Marshal(stats)
+	if err != nil {
+		log.Printf("Failed to encode tick stats: %v", err)
+		return
+	}
+	if err := g.redis.Set(ctx, tickStatsKey, payload, 0).Err(); err != nil {
+		log.Printf("Failed to save tick stats: %v", err)
+	}
+}
+
+// WeatherStatus is the current weather and when it changes
+type WeatherStatus struct {
+	Current          *models.Weather `json:"current"`
+	ExpiresAt        time.Time       `json:"expires_at"`
+	ExpiresInSeconds int64           `json:"expires_in_seconds"`
+}
+
+// TickStatus is the schedule of the background simulation
+type TickStatus struct {
+	IntervalSeconds   int64     `json:"interval_seconds"`
+	NextTickAt        time.Time `json:"next_tick_at"`
+	NextTickInSeconds int64     `json:"next_tick_in_seconds"`
+}
+
+// EngineHealth reports whether the simulation is keeping up
+type EngineHealth struct {
+	*TickStats
+	// CurrentLeaderID is the replica holding the leader lease now, empty when none does
+	CurrentLeaderID string `json:"current_leader_id"`
+	Stalled         bool   `json:"stalled"`
+}
+
+// GameStatus is a snapshot of the game world
+type GameStatus struct {
+	ServerTime   time.Time      `json:"server_time"`
+	Season       models.Season  `json:"season"`
+	SeasonEndsAt time.Time      `json:"season_ends_at"`
+	Weather      *WeatherStatus `json:"weather"`
+	Tick         TickStatus     `json:"tick"`
+	Engine       EngineHealth   `json:"engine"`
+}
+
+// Status reports the game clock, weather and the health of the simulation
+func (g *GameEngine) Status(ctx context.Context) *GameStatus {
+	now := time.Now().UTC()
+	interval := g.config.Game.PlantGrowthInterval
+
+	status := &GameStatus{
+		ServerTime:   now,
+		Season:       models.GetSeason(now),
+		SeasonEndsAt: nextSeasonStart(now),
+	}
+
+	if weather, err := g.GetCurrentWeather(); err == nil {
+		status.Weather = &WeatherStatus{
+			Current:          weather,
+			ExpiresAt:        weather.ValidUntil,
+			ExpiresInSeconds: secondsUntil(now, weather.ValidUntil),
+		}
+	}
+
+	stats := g.loadTickStats(ctx)
+	status.Tick = TickStatus{
+		IntervalSeconds:   int64(interval.Seconds()),
+		NextTickAt:        stats.NextTickAt,
+		NextTickInSeconds: secondsUntil(now, stats.NextTickAt),
+	}
+
+	// Nobody advances the simulation without a leader, and a tick a whole interval
+	// overdue means the leader isn't either. Without Redis nobody can hold the lease.
+	leader, err := g.elector.Info(ctx)
+	if err != nil {
+		log.Printf("Failed to look up the game leader: %v", err)
+	}
+	status.Engine = EngineHealth{
+		TickStats:       stats,
+		CurrentLeaderID: leader.LeaderID,
+		Stalled:         leader.LeaderID == "" || stats.NextTickAt.IsZero() || now.After(stats.NextTickAt.Add(interval)),
+	}
+
+	return status
+}
+
+// secondsUntil counts down to t, stopping at zero
+func secondsUntil(now, t time.Time) int64 {
+	if t.Before(now) {
+		return 0
+	}
+	return int64(t.Sub(now).Seconds())
+}
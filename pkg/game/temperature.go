@@ -0,0 +1,73 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/my-garden/api/internal/models"
+)
+
+const (
+	// toleranceGrowth is how fast a plant grows right at its frost or heat threshold,
+	// falling to it from full speed at the edge of its optimal range
+	toleranceGrowth = 0.5
+
+	// stressGrowth is how fast a plant grows beyond its frost or heat threshold
+	stressGrowth = 0.3
+
+	// A plant beyond its frost or heat threshold loses stressHealth health per tick,
+	// plus stressHealthPerDegree for every degree further out
+	stressHealth          = 1.0
+	stressHealthPerDegree = 0.5
+)
+
+// temperatureEffect returns how the temperature affects a plant of the given type.
+// A greenhouse keeps off frost and heat damage, but plants still grow slowly in it
+// when it is far too cold or hot for them.
+func temperatureEffect(plantType *models.PlantType, env Environment) careBand {
+	temperature := env.Weather.Temperature
+
+	switch {
+	case temperature < plantType.FrostThreshold:
+		return temperatureStress(plantType.FrostThreshold-temperature, env.Greenhouse,
+			models.DeathFrost, fmt.Sprintf("frost: %.1f°C is below the %.1f°C it tolerates", temperature, plantType.FrostThreshold))
+	case temperature > plantType.HeatThreshold:
+		return temperatureStress(temperature-plantType.HeatThreshold, env.Greenhouse,
+			models.DeathHeat, fmt.Sprintf("heat stress: %.1f°C is above the %.1f°C it tolerates", temperature, plantType.HeatThreshold))
+	case temperature < plantType.OptimalTempMin:
+		return careBand{
+			Growth: toleranceScale(plantType.OptimalTempMin-temperature, plantType.OptimalTempMin-plantType.FrostThreshold),
+			Reason: fmt.Sprintf("a little cold: %.1f°C, it prefers %.0f-%.0f°C", temperature, plantType.OptimalTempMin, plantType.OptimalTempMax),
+		}
+	case temperature > plantType.OptimalTempMax:
+		return careBand{
+			Growth: toleranceScale(temperature-plantType.OptimalTempMax, plantType.HeatThreshold-plantType.OptimalTempMax),
+			Reason: fmt.Sprintf("a little hot: %.1f°C, it prefers %.0f-%.0f°C", temperature, plantType.OptimalTempMin, plantType.OptimalTempMax),
+		}
+	default:
+		return careBand{Growth: 1.0, Reason: fmt.Sprintf("%.1f°C is just right", temperature)}
+	}
+}
+
+// toleranceScale slows growth the further a temperature is outside the optimal range,
+// across a tolerance band of the given width
+func toleranceScale(distance, width float64) float64 {
+	if width <= 0 {
+		return toleranceGrowth
+	}
+	return 1 - (1-toleranceGrowth)*math.Min(1, distance/width)
+}
+
+// temperatureStress is the effect of a temperature the given number of degrees beyond
+// what a plant tolerates
+func temperatureStress(degrees float64, sheltered bool, cause models.DeathReason, reason string) careBand {
+	if sheltered {
+		return careBand{Growth: stressGrowth, Reason: reason + ", but the greenhouse keeps it from harm"}
+	}
+	return careBand{
+		Growth: stressGrowth,
+		Health: -(stressHealth + stressHealthPerDegree*degrees),
+		Reason: reason,
+		Cause:  cause,
+	}
+}
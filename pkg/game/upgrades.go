@@ -0,0 +1,167 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/my-garden/api/internal/models"
+	"gorm.io/gorm"
+)
+
+// UpgradeType identifies a garden upgrade
+type UpgradeType string
+
+const (
+	UpgradeSprinkler  UpgradeType = "sprinkler"
+	UpgradeGreenhouse UpgradeType = "greenhouse"
+	UpgradeComposter  UpgradeType = "composter"
+)
+
+// Upgrade describes a garden upgrade players can buy
+type Upgrade struct {
+	Type        UpgradeType `json:"type"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Cost        int         `json:"cost"`
+	MinLevel    int         `json:"min_level"`
+}
+
+// Upgrades lists every garden upgrade in the shop
+var Upgrades = []Upgrade{
+	{
+		Type:        UpgradeSprinkler,
+		Name:        "Sprinkler",
+		Description: "Automatically waters plants before they dry out",
+		Cost:        150,
+		MinLevel:    2,
+	},
+	{
+		Type:        UpgradeGreenhouse,
+		Name:        "Greenhouse",
+		Description: "Shelters plants from snow, storms, frost and most extreme weather",
+		Cost:        400,
+		MinLevel:    5,
+	},
+	{
+		Type:        UpgradeComposter,
+		Name:        "Composter",
+		Description: "Turns withered plants into fertilizer for new seedlings",
+		Cost:        200,
+		MinLevel:    3,
+	},
+}
+
+// GetUpgrade looks up an upgrade by type
+func GetUpgrade(upgradeType UpgradeType) (Upgrade, bool) {
+	for _, upgrade := range Upgrades {
+		if upgrade.Type == upgradeType {
+			return upgrade, true
+		}
+	}
+	return Upgrade{}, false
+}
+
+// Column returns the garden column that records the upgrade
+func (u Upgrade) Column() string {
+	return "has_" + string(u.Type)
+}
+
+// Installed reports whether a garden already has the upgrade
+func (u Upgrade) Installed(garden *models.Garden) bool {
+	switch u.Type {
+	case UpgradeSprinkler:
+		return garden.HasSprinkler
+	case UpgradeGreenhouse:
+		return garden.HasGreenhouse
+	case UpgradeComposter:
+		return garden.HasComposter
+	default:
+		return false
+	}
+}
+
+const (
+	// The sprinkler tops plants up once their water falls this far below their needs
+	sprinklerBelow = -10.0
+	sprinklerAbove = 10.0
+
+	// Withered plants are composted once they have been dead this long
+	compostDelay = time.Hour
+
+	// Garden fertilizer gained per composted plant
+	compostPerPlant = 15
+)
+
+var stormDamage = careBand{Growth: 1.0, Health: -1, Cause: models.DeathStorm}
+
+// sprinklerLevel is the water level at which the sprinkler kicks in
+func sprinklerLevel(needs int) float64 {
+	return float64(needs) + sprinklerBelow
+}
+
+// sprinklerTarget is the water level the sprinkler tops a plant up to
+func sprinklerTarget(needs int) float64 {
+	return float64(needs) + sprinklerAbove
+}
+
+// climateEffect returns how the weather itself affects a plant, taking shelter into
+// account. Temperature is judged separately, against what the species tolerates.
+func climateEffect(env Environment) careBand {
+	weather := env.Weather
+	harsh := weather.Condition == models.WeatherSnowy || weather.Condition == models.WeatherStormy
+
+	switch {
+	case env.Greenhouse && harsh:
+		return careBand{Growth: 1.0, Reason: fmt.Sprintf("sheltered in the greenhouse from the %s weather", weather.Condition)}
+	case weather.Condition == models.WeatherStormy:
+		band := stormDamage
+		band.Growth *= weather.GrowthMultiplier
+		band.Reason = "storm: wind and rain are battering it"
+		return band
+	default:
+		return careBand{Growth: weather.GrowthMultiplier, Reason: fmt.Sprintf("%s weather", weather.Condition)}
+	}
+}
+
+// compostWithered turns plants that have been dead for a while into fertilizer for
+// gardens with a composter, freeing their spot. It returns how many plants were
+// composted and how many failed.
+func (g *GameEngine) compostWithered() (composted, failed int) {
+	cutoff := time.Now().Add(-compostDelay)
+
+	var plants []models.Plant
+	if err := g.db.DB.Joins("JOIN gardens ON plants.garden_id = gardens.id").
+		Preload("Garden").
+		Where("gardens.has_composter = ? AND plants.stage = ?", true, models.PlantStageWithered).
+		Where("COALESCE(plants.withered_at, plants.harvested_at, plants.updated_at) < ?", cutoff).
+		Find(&plants).Error; err != nil {
+		log.Printf("Failed to find plants to compost: %v", err)
+		return 0, 1
+	}
+
+	for i := range plants {
+		plant := &plants[i]
+
+		err := g.db.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&models.Plant{}, plant.ID).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.Garden{}).Where("id = ?", plant.GardenID).
+				UpdateColumn("fertilizer_level", gorm.Expr("LEAST(100, fertilizer_level + ?)", compostPerPlant)).Error
+		})
+		if err != nil {
+			log.Printf("Failed to compost plant %s: %v", plant.ID, err)
+			failed++
+			continue
+		}
+
+		composted++
+		g.PublishEvent(NewPlantUpdatedEvent(plant.Garden.UserID, UpdatePlantComposted, plant))
+	}
+
+	if composted > 0 {
+		log.Printf("Composted %d withered plants", composted)
+	}
+	return composted, failed
+}
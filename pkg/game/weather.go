@@ -0,0 +1,194 @@
+package game
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/my-garden/api/internal/config"
+	"github.com/my-garden/api/internal/models"
+)
+
+// MarkovWeather is the game's own weather model, a Markov chain: each update draws the
+// next condition from the row of the season's transition matrix for the current
+// condition, and temperature, humidity and wind drift from where they were towards
+// what suits the season and the new condition. It is safe for concurrent use.
+type MarkovWeather struct {
+	transitions config.WeatherTransitions
+}
+
+// NewMarkovWeather creates a weather model driven by the given transitions
+func NewMarkovWeather(transitions config.WeatherTransitions) *MarkovWeather {
+	return &MarkovWeather{transitions: transitions}
+}
+
+// Weather draws the weather that follows previous at the given time
+func (m *MarkovWeather) Weather(ctx context.Context, previous models.Weather, at time.Time) (models.Weather, error) {
+	season := models.GetSeason(at)
+
+	// Draw the next condition from the season's transition matrix, then let the
+	// readings drift from where they were
+	condition := m.nextCondition(season, previous.Condition)
+	weather := models.Weather{
+		Condition:   condition,
+		Temperature: nextTemperature(previous.Temperature, season, condition),
+		Humidity:    nextHumidity(previous.Humidity, condition),
+		WindSpeed:   nextWindSpeed(previous.WindSpeed, condition),
+		Event:       nextEvent(season, previous.Event, condition),
+	}
+	applyEventReadings(&weather)
+	return weather, nil
+}
+
+// Ahead is true: the model can draw the weather as far ahead as asked
+func (m *MarkovWeather) Ahead() bool {
+	return true
+}
+
+// How strongly each weather reading is pulled towards its norm on every update, as
+// the share of the gap closed, and how much random noise is added on top
+const (
+	temperatureReversion = 0.3
+	temperatureNoise     = 1.0 // °C, standard deviation
+	humidityReversion    = 0.4
+	humidityNoise        = 4.0 // percentage points
+	windReversion        = 0.5
+	windNoise            = 2.0 // km/h
+)
+
+// conditionTemperature shifts a season's base temperature for each condition
+var conditionTemperature = map[models.WeatherCondition]float64{
+	models.WeatherSunny:  4.5,
+	models.WeatherCloudy: 0.5,
+	models.WeatherRainy:  -1.0,
+	models.WeatherStormy: -1.5,
+	models.WeatherFoggy:  0,
+	models.WeatherWindy:  0,
+	models.WeatherSnowy:  -3.5,
+}
+
+// conditionHumidity is the humidity each condition tends towards
+var conditionHumidity = map[models.WeatherCondition]float64{
+	models.WeatherSunny:  40,
+	models.WeatherCloudy: 60,
+	models.WeatherRainy:  85,
+	models.WeatherStormy: 90,
+	models.WeatherFoggy:  95,
+	models.WeatherWindy:  45,
+	models.WeatherSnowy:  80,
+}
+
+// conditionWind is the wind speed each condition tends towards
+var conditionWind = map[models.WeatherCondition]float64{
+	models.WeatherSunny:  6,
+	models.WeatherCloudy: 10,
+	models.WeatherRainy:  12,
+	models.WeatherStormy: 40,
+	models.WeatherFoggy:  3,
+	models.WeatherWindy:  30,
+	models.WeatherSnowy:  15,
+}
+
+// transitionProbabilities returns the chance of each condition following from in the
+// given season. A condition the season has no row for, such as snow lingering into
+// spring, moves as if from any of the season's conditions.
+func (m *MarkovWeather) transitionProbabilities(season models.Season, from models.WeatherCondition) map[models.WeatherCondition]float64 {
+	rows := m.transitions[season]
+
+	weights := rows[from]
+	if len(weights) == 0 {
+		weights = make(map[models.WeatherCondition]float64)
+		for _, row := range rows {
+			for to, weight := range row {
+				weights[to] += weight
+			}
+		}
+	}
+
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	probabilities := make(map[models.WeatherCondition]float64, len(weights))
+	if total <= 0 {
+		// No transitions configured for the season; keep the weather as it is
+		probabilities[from] = 1
+		return probabilities
+	}
+	for to, weight := range weights {
+		probabilities[to] = weight / total
+	}
+	return probabilities
+}
+
+// nextCondition draws the condition that follows from in the given season
+func (m *MarkovWeather) nextCondition(season models.Season, from models.WeatherCondition) models.WeatherCondition {
+	probabilities := m.transitionProbabilities(season, from)
+
+	// Walk the conditions in a fixed order so a draw only depends on the random number
+	conditions := make([]models.WeatherCondition, 0, len(probabilities))
+	for condition := range probabilities {
+		conditions = append(conditions, condition)
+	}
+	sort.Slice(conditions, func(i, j int) bool { return conditions[i] < conditions[j] })
+
+	roll := rand.Float64()
+	for _, condition := range conditions {
+		roll -= probabilities[condition]
+		if roll < 0 {
+			return condition
+		}
+	}
+	return conditions[len(conditions)-1]
+}
+
+// stepDistribution moves a spread of chances over the conditions on by one weather update
+func (m *MarkovWeather) stepDistribution(distribution map[models.WeatherCondition]float64, season models.Season) map[models.WeatherCondition]float64 {
+	next := make(map[models.WeatherCondition]float64)
+	for from, chance := range distribution {
+		for to, probability := range m.transitionProbabilities(season, from) {
+			next[to] += chance * probability
+		}
+	}
+	return next
+}
+
+// drift moves a reading part of the way towards its norm and adds noise
+func drift(previous, norm, reversion, noise float64) float64 {
+	return previous + (norm-previous)*reversion + rand.NormFloat64()*noise
+}
+
+// nextTemperature drifts from the previous temperature towards the season and condition's norm
+func nextTemperature(previous float64, season models.Season, condition models.WeatherCondition) float64 {
+	norm := getBaseTemperatureForSeason(season) + conditionTemperature[condition]
+	return math.Round(drift(previous, norm, temperatureReversion, temperatureNoise)*10) / 10
+}
+
+// nextHumidity drifts from the previous humidity towards the condition's norm
+func nextHumidity(previous int, condition models.WeatherCondition) int {
+	humidity := drift(float64(previous), conditionHumidity[condition], humidityReversion, humidityNoise)
+	return int(math.Round(math.Min(100, math.Max(5, humidity))))
+}
+
+// nextWindSpeed drifts from the previous wind speed towards the condition's norm
+func nextWindSpeed(previous float64, condition models.WeatherCondition) float64 {
+	wind := drift(previous, conditionWind[condition], windReversion, windNoise)
+	return math.Round(math.Max(0, wind)*10) / 10
+}
+
+func getBaseTemperatureForSeason(season models.Season) float64 {
+	switch season {
+	case models.SeasonSpring:
+		return 15.0
+	case models.SeasonSummer:
+		return 25.0
+	case models.SeasonAutumn:
+		return 15.0
+	case models.SeasonWinter:
+		return 5.0
+	default:
+		return 15.0
+	}
+}
@@ -0,0 +1,164 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/my-garden/api/internal/models"
+)
+
+// extremeEvent describes a kind of extreme weather
+type extremeEvent struct {
+	// Chance of the event breaking out at a weather update in each season, given one of
+	// its conditions. Seasons that are missing never see it.
+	Chances    map[models.Season]float64
+	Conditions []models.WeatherCondition // weather the event comes with
+
+	Damage      careBand // effect on plants outside a greenhouse
+	Shelter     float64  // share of the damage a greenhouse keeps off
+	Evaporation float64  // multiplies water evaporation while it lasts
+	Advice      string   // shown with warnings
+}
+
+// eventPersistence is the chance an event carries on into the next weather update,
+// as long as the weather still suits it
+const eventPersistence = 0.75
+
+var extremeEvents = map[models.WeatherEvent]extremeEvent{
+	models.WeatherEventHail: {
+		Chances:     map[models.Season]float64{models.SeasonSpring: 0.03, models.SeasonSummer: 0.05},
+		Conditions:  []models.WeatherCondition{models.WeatherStormy, models.WeatherRainy},
+		Damage:      careBand{Growth: 0.5, Health: -6, Regress: 4, Cause: models.DeathHail},
+		Shelter:     1.0,
+		Evaporation: 1.0,
+		Advice:      "Hailstones shred leaves and knock plants back a stage. Only a greenhouse keeps them off.",
+	},
+	models.WeatherEventFrost: {
+		Chances:     map[models.Season]float64{models.SeasonSpring: 0.01, models.SeasonAutumn: 0.015, models.SeasonWinter: 0.03},
+		Conditions:  []models.WeatherCondition{models.WeatherSunny, models.WeatherCloudy, models.WeatherFoggy},
+		Damage:      careBand{Growth: 0.3, Health: -5, Cause: models.DeathFrost},
+		Shelter:     0.8,
+		Evaporation: 0.5,
+		Advice:      "A hard frost is coming. Tender plants outside a greenhouse may not survive it.",
+	},
+	models.WeatherEventHeatwave: {
+		Chances:     map[models.Season]float64{models.SeasonSpring: 0.003, models.SeasonSummer: 0.02},
+		Conditions:  []models.WeatherCondition{models.WeatherSunny},
+		Damage:      careBand{Growth: 0.7, Health: -3, Cause: models.DeathHeatwave},
+		Shelter:     0.5,
+		Evaporation: 2.0,
+		Advice:      "Scorching heat dries soil out twice as fast. Water well ahead of it.",
+	},
+	models.WeatherEventDrought: {
+		Chances:     map[models.Season]float64{models.SeasonSummer: 0.01, models.SeasonAutumn: 0.004},
+		Conditions:  []models.WeatherCondition{models.WeatherSunny, models.WeatherWindy},
+		Damage:      careBand{Growth: 0.6, Health: -1, Cause: models.DeathDrought},
+		Shelter:     0.5,
+		Evaporation: 2.5,
+		Advice:      "Bone-dry air parches plants and soil. Keep them watered.",
+	},
+	models.WeatherEventFlood: {
+		Chances:     map[models.Season]float64{models.SeasonSpring: 0.02, models.SeasonSummer: 0.01, models.SeasonAutumn: 0.03},
+		Conditions:  []models.WeatherCondition{models.WeatherRainy, models.WeatherStormy},
+		Damage:      careBand{Growth: 0.5, Health: -4, Regress: 2, Cause: models.DeathFlood},
+		Shelter:     0.75,
+		Evaporation: 0.1,
+		Advice:      "Floodwater drowns roots and washes seedlings back. A greenhouse keeps most of it out.",
+	},
+}
+
+// eventOrder lists the events in a fixed order so a draw only depends on the random numbers
+var eventOrder = func() []models.WeatherEvent {
+	events := make([]models.WeatherEvent, 0, len(extremeEvents))
+	for event := range extremeEvents {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i] < events[j] })
+	return events
+}()
+
+// comesWith reports whether an event can happen in the given weather
+func (e extremeEvent) comesWith(condition models.WeatherCondition) bool {
+	for _, c := range e.Conditions {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// nextEvent draws the extreme weather, if any, that comes with a weather update.
+// Events carry on while the weather suits them, and otherwise break out rarely.
+func nextEvent(season models.Season, previous models.WeatherEvent, condition models.WeatherCondition) models.WeatherEvent {
+	if ongoing, ok := extremeEvents[previous]; ok && ongoing.comesWith(condition) && rand.Float64() < eventPersistence {
+		return previous
+	}
+
+	for _, event := range eventOrder {
+		extreme := extremeEvents[event]
+		if extreme.comesWith(condition) && rand.Float64() < extreme.Chances[season] {
+			return event
+		}
+	}
+	return ""
+}
+
+// applyEventReadings pushes the readings of weather with an extreme event to match it
+func applyEventReadings(weather *models.Weather) {
+	switch weather.Event {
+	case models.WeatherEventFrost:
+		weather.Temperature = math.Min(weather.Temperature, -3)
+	case models.WeatherEventHeatwave:
+		weather.Temperature = math.Max(weather.Temperature, 34)
+		weather.Humidity = min(weather.Humidity, 30)
+	case models.WeatherEventDrought:
+		weather.Humidity = min(weather.Humidity, 15)
+	case models.WeatherEventFlood:
+		weather.Humidity = max(weather.Humidity, 95)
+	case models.WeatherEventHail:
+		weather.Temperature = math.Min(weather.Temperature, 12)
+	}
+}
+
+// eventFromReadings recognises extreme weather from readings alone, for providers
+// that only report measurements
+func eventFromReadings(weather models.Weather) models.WeatherEvent {
+	switch {
+	case weather.Temperature <= -3:
+		return models.WeatherEventFrost
+	case weather.Temperature >= 34:
+		return models.WeatherEventHeatwave
+	default:
+		return ""
+	}
+}
+
+// eventEffect returns how an extreme weather event affects a plant, taking shelter
+// into account. It reports false if there is no event.
+func eventEffect(env Environment) (careBand, bool) {
+	extreme, ok := extremeEvents[env.Weather.Event]
+	if !ok {
+		return careBand{Growth: 1.0}, false
+	}
+
+	band := extreme.Damage
+	band.Reason = fmt.Sprintf("%s: %s", env.Weather.Event, extreme.Advice)
+	if env.Greenhouse {
+		kept := 1 - extreme.Shelter
+		band.Growth = 1 - (1-band.Growth)*kept
+		band.Health *= kept
+		band.Regress *= kept
+		band.Reason = fmt.Sprintf("%s: the greenhouse keeps off %.0f%% of it", env.Weather.Event, extreme.Shelter*100)
+	}
+	return band, true
+}
+
+// eventEvaporation is how much an extreme weather event speeds up water evaporation
+func eventEvaporation(event models.WeatherEvent) float64 {
+	if extreme, ok := extremeEvents[event]; ok {
+		return extreme.Evaporation
+	}
+	return 1.0
+}
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/my-garden/api/internal/models"
+)
+
+// windyFrom is the wind speed, in km/h, from which dry weather counts as windy
+const windyFrom = 40.0
+
+// openWeatherConditions maps OpenWeather's main weather groups to game conditions
+var openWeatherConditions = map[string]models.WeatherCondition{
+	"Clear":        models.WeatherSunny,
+	"Clouds":       models.WeatherCloudy,
+	"Drizzle":      models.WeatherRainy,
+	"Rain":         models.WeatherRainy,
+	"Thunderstorm": models.WeatherStormy,
+	"Snow":         models.WeatherSnowy,
+	"Mist":         models.WeatherFoggy,
+	"Fog":          models.WeatherFoggy,
+	"Haze":         models.WeatherFoggy,
+	"Smoke":        models.WeatherFoggy,
+	"Dust":         models.WeatherFoggy,
+	"Sand":         models.WeatherFoggy,
+	"Ash":          models.WeatherFoggy,
+	"Squall":       models.WeatherWindy,
+	"Tornado":      models.WeatherStormy,
+}
+
+// openWeatherResponse is the part of an OpenWeather current weather response the game uses
+type openWeatherResponse struct {
+	Weather []struct {
+		Main string `json:"main"`
+	} `json:"weather"`
+	Main struct {
+		Temp     float64 `json:"temp"`
+		Humidity int     `json:"humidity"`
+		Pressure float64 `json:"pressure"`
+	} `json:"main"`
+	Wind struct {
+		Speed float64 `json:"speed"` // m/s
+	} `json:"wind"`
+}
+
+// OpenWeatherProvider takes the weather from an OpenWeather-style current weather API,
+// or anything that answers like one, such as a local stub
+type OpenWeatherProvider struct {
+	endpoint string
+	query    url.Values
+	client   *http.Client
+}
+
+// NewOpenWeatherProvider reads the weather at a location from the given endpoint
+func NewOpenWeatherProvider(endpoint, apiKey, latitude, longitude string) (*OpenWeatherProvider, error) {
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid weather API URL %q: %w", endpoint, err)
+	}
+
+	query := url.Values{}
+	query.Set("lat", latitude)
+	query.Set("lon", longitude)
+	query.Set("units", "metric")
+	if apiKey != "" {
+		query.Set("appid", apiKey)
+	}
+
+	return &OpenWeatherProvider{
+		endpoint: endpoint,
+		query:    query,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Weather fetches the weather now; the API can't tell the weather at other times
+func (p *OpenWeatherProvider) Weather(ctx context.Context, previous models.Weather, at time.Time) (models.Weather, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint+"?"+p.query.Encode(), nil)
+	if err != nil {
+		return models.Weather{}, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return models.Weather{}, fmt.Errorf("failed to fetch weather: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return models.Weather{}, fmt.Errorf("weather API returned %s", resp.Status)
+	}
+
+	var body openWeatherResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return models.Weather{}, fmt.Errorf("failed to parse weather: %w", err)
+	}
+
+	windSpeed := math.Round(body.Wind.Speed*3.6*10) / 10 // m/s to km/h
+	weather := models.Weather{
+		Condition:   openWeatherCondition(body, windSpeed),
+		Temperature: math.Round(body.Main.Temp*10) / 10,
+		Humidity:    body.Main.Humidity,
+		WindSpeed:   windSpeed,
+		Pressure:    body.Main.Pressure,
+	}
+	weather.Event = eventFromReadings(weather)
+	return weather, nil
+}
+
+// Ahead is false: the API only tells the weather now
+func (p *OpenWeatherProvider) Ahead() bool {
+	return false
+}
+
+// openWeatherCondition maps a response to the game condition closest to it
+func openWeatherCondition(body openWeatherResponse, windSpeed float64) models.WeatherCondition {
+	condition := models.WeatherCloudy
+	if len(body.Weather) > 0 {
+		if mapped, ok := openWeatherConditions[body.Weather[0].Main]; ok {
+			condition = mapped
+		}
+	}
+
+	// OpenWeather has no windy group, so strong wind on a dry day makes it windy
+	if (condition == models.WeatherSunny || condition == models.WeatherCloudy) && windSpeed >= windyFrom {
+		return models.WeatherWindy
+	}
+	return condition
+}
@@ -0,0 +1,132 @@
+package game
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/my-garden/api/internal/config"
+	"github.com/my-garden/api/internal/models"
+)
+
+// openWeatherStub answers every request with the given status and body, and checks the
+// provider asks for the configured place in metric units
+func openWeatherStub(t *testing.T, status int, body string) *OpenWeatherProvider {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("lat") != "51.5" || query.Get("lon") != "-0.1" || query.Get("units") != "metric" || query.Get("appid") != "key" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	provider, err := NewOpenWeatherProvider(server.URL, "key", "51.5", "-0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestOpenWeatherProvider(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want models.Weather
+	}{
+		{
+			name: "clear",
+			body: `{"weather":[{"main":"Clear"}],"main":{"temp":21.46,"humidity":40,"pressure":1020},"wind":{"speed":2.5}}`,
+			want: models.Weather{Condition: models.WeatherSunny, Temperature: 21.5, Humidity: 40, WindSpeed: 9, Pressure: 1020},
+		},
+		{
+			name: "rain",
+			body: `{"weather":[{"main":"Rain"}],"main":{"temp":12,"humidity":90,"pressure":1004},"wind":{"speed":5}}`,
+			want: models.Weather{Condition: models.WeatherRainy, Temperature: 12, Humidity: 90, WindSpeed: 18, Pressure: 1004},
+		},
+		{
+			name: "thunderstorm",
+			body: `{"weather":[{"main":"Thunderstorm"}],"main":{"temp":18,"humidity":85,"pressure":998},"wind":{"speed":8}}`,
+			want: models.Weather{Condition: models.WeatherStormy, Temperature: 18, Humidity: 85, WindSpeed: 28.8, Pressure: 998},
+		},
+		{
+			name: "strong wind on a cloudy day is windy",
+			body: `{"weather":[{"main":"Clouds"}],"main":{"temp":14,"humidity":60,"pressure":1008},"wind":{"speed":12}}`,
+			want: models.Weather{Condition: models.WeatherWindy, Temperature: 14, Humidity: 60, WindSpeed: 43.2, Pressure: 1008},
+		},
+		{
+			name: "strong wind in the rain stays rainy",
+			body: `{"weather":[{"main":"Rain"}],"main":{"temp":10,"humidity":95,"pressure":990},"wind":{"speed":15}}`,
+			want: models.Weather{Condition: models.WeatherRainy, Temperature: 10, Humidity: 95, WindSpeed: 54, Pressure: 990},
+		},
+		{
+			name: "unknown group is cloudy",
+			body: `{"weather":[{"main":"Aurora"}],"main":{"temp":5,"humidity":70,"pressure":1010},"wind":{"speed":1}}`,
+			want: models.Weather{Condition: models.WeatherCloudy, Temperature: 5, Humidity: 70, WindSpeed: 3.6, Pressure: 1010},
+		},
+		{
+			name: "frost",
+			body: `{"weather":[{"main":"Snow"}],"main":{"temp":-4.2,"humidity":80,"pressure":1025},"wind":{"speed":1}}`,
+			want: models.Weather{Condition: models.WeatherSnowy, Temperature: -4.2, Humidity: 80, WindSpeed: 3.6, Pressure: 1025, Event: models.WeatherEventFrost},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := openWeatherStub(t, http.StatusOK, tt.body)
+			got, err := provider.Weather(context.Background(), defaultWeather, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("weather = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenWeatherProviderFailureFallsBackToModel(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "server error", status: http.StatusInternalServerError, body: `{"message":"internal error"}`},
+		{name: "unauthorized", status: http.StatusUnauthorized, body: `{"cod":401,"message":"Invalid API key"}`},
+		{name: "malformed body", status: http.StatusOK, body: `{"weather":[{"main":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := openWeatherStub(t, tt.status, tt.body)
+			if _, err := provider.Weather(context.Background(), defaultWeather, time.Now()); err == nil {
+				t.Fatal("expected an error")
+			}
+
+			cfg := &config.Config{}
+			cfg.Game.WeatherUpdateInterval = 30 * time.Minute
+			g := &GameEngine{
+				config:          cfg,
+				ctx:             context.Background(),
+				weatherModel:    NewMarkovWeather(nil),
+				weatherProvider: provider,
+			}
+
+			at := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+			got := g.weatherAt(defaultWeather, at)
+			if !got.Condition.Valid() {
+				t.Errorf("fallback condition %q is not valid", got.Condition)
+			}
+			if got.GrowthMultiplier == 0 {
+				t.Error("fallback weather has no effects on plants")
+			}
+			if want := at.Add(cfg.Game.WeatherUpdateInterval); !got.ValidUntil.Equal(want) {
+				t.Errorf("valid until %s, want %s", got.ValidUntil, want)
+			}
+		})
+	}
+}
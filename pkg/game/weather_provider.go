@@ -0,0 +1,117 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/my-garden/api/internal/config"
+	"github.com/my-garden/api/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Names of the weather providers shipped with the game. WEATHER_PROVIDER selects one.
+const (
+	WeatherProviderRandom = "random"
+	WeatherProviderReplay = "replay"
+	WeatherProviderHTTP   = "http"
+)
+
+// replayStartKey is where the time a replay started playing is kept, so every replica
+// and restart plays the same reading at the same time
+const replayStartKey = "game:weather_replay_started"
+
+// WeatherProvider supplies the weather at each weather update. Providers only need to
+// fill in the condition, readings and any extreme event; the engine works out the
+// effects on plants.
+type WeatherProvider interface {
+	// Weather returns the weather at the given time. previous is the weather before it,
+	// for providers that move on from where the weather was.
+	Weather(ctx context.Context, previous models.Weather, at time.Time) (models.Weather, error)
+
+	// Ahead reports whether the provider knows the weather at future times. The
+	// forecast of providers that do is what will happen; providers that only know the
+	// weather now are asked at every update and forecast with the game's own model.
+	Ahead() bool
+}
+
+// NewWeatherProvider creates the weather provider the configuration selects. Redis
+// keeps the time a replay started when the configuration doesn't give one.
+func NewWeatherProvider(ctx context.Context, cfg *config.Config, rdb *redis.Client) (WeatherProvider, error) {
+	switch cfg.Weather.Provider {
+	case "", WeatherProviderRandom:
+		return NewMarkovWeather(cfg.Game.WeatherTransitions), nil
+	case WeatherProviderReplay:
+		started, err := replayStart(ctx, cfg.Weather, rdb)
+		if err != nil {
+			return nil, err
+		}
+		return NewReplayWeather(cfg.Weather.ReplayFile, cfg.Weather.ReplayFrom, started)
+	case WeatherProviderHTTP:
+		return NewOpenWeatherProvider(cfg.Weather.APIURL, cfg.Weather.APIKey, cfg.Weather.Latitude, cfg.Weather.Longitude)
+	default:
+		return nil, fmt.Errorf("unknown weather provider %q", cfg.Weather.Provider)
+	}
+}
+
+// replayStart returns the wall-clock time the replay started playing: the configured
+// time, or else the first start of this recording, which Redis keeps. Without Redis
+// the replay plays from now, and replicas may disagree on the weather.
+func replayStart(ctx context.Context, cfg config.WeatherConfig, rdb *redis.Client) (time.Time, error) {
+	if cfg.ReplayStart != "" {
+		started, err := time.Parse(time.RFC3339, cfg.ReplayStart)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid weather replay start time %q: %w", cfg.ReplayStart, err)
+		}
+		return started, nil
+	}
+
+	// A different recording or starting point plays from its own first start
+	key := replayStartKey + ":" + cfg.ReplayFile + "@" + cfg.ReplayFrom
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	if err := rdb.SetNX(ctx, key, now, 0).Err(); err != nil {
+		log.Printf("Failed to record when the weather replay started, playing from now: %v", err)
+		return time.Now(), nil
+	}
+	value, err := rdb.Get(ctx, key).Result()
+	if err != nil {
+		log.Printf("Failed to load when the weather replay started, playing from now: %v", err)
+		return time.Now(), nil
+	}
+	started, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid weather replay start time %q in Redis: %w", value, err)
+	}
+	return started, nil
+}
+
+// SetWeatherProvider replaces where the weather comes from. Call it before Start.
+func (g *GameEngine) SetWeatherProvider(provider WeatherProvider) {
+	g.weatherProvider = provider
+}
+
+// weatherAt asks the weather provider for the weather at the given time, drawing it
+// from the game's own model instead if the provider fails
+func (g *GameEngine) weatherAt(previous models.Weather, at time.Time) models.Weather {
+	weather, err := g.weatherProvider.Weather(g.ctx, previous, at)
+	if err != nil || !weather.Condition.Valid() {
+		log.Printf("Weather provider failed, drawing the weather instead: %v", err)
+		weather, _ = g.weatherModel.Weather(g.ctx, previous, at)
+	}
+	return g.completeWeather(weather, at)
+}
+
+// completeWeather adds the effects on plants to a provider's weather from the given time
+func (g *GameEngine) completeWeather(weather models.Weather, at time.Time) models.Weather {
+	weather.GrowthMultiplier, weather.WaterEvaporationRate = models.GetWeatherEffects(weather.Condition)
+	if !weather.Event.Valid() {
+		weather.Event = ""
+	}
+	weather.WaterEvaporationRate *= eventEvaporation(weather.Event)
+	if weather.Pressure == 0 {
+		weather.Pressure = 1013.25 // Standard atmospheric pressure
+	}
+	weather.ValidUntil = at.Add(g.config.Game.WeatherUpdateInterval)
+	return weather
+}
@@ -0,0 +1,194 @@
+package game
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/my-garden/api/internal/models"
+)
+
+// WeatherRecord is one reading in a weather replay file
+type WeatherRecord struct {
+	Timestamp   time.Time               `json:"timestamp"`
+	Condition   models.WeatherCondition `json:"condition"`
+	Temperature float64                 `json:"temperature"`
+	Humidity    int                     `json:"humidity"`
+	WindSpeed   float64                 `json:"wind_speed"`
+	Event       models.WeatherEvent     `json:"event,omitempty"` // optional extreme weather
+}
+
+// replayColumns are the columns a CSV replay file must have, in any order. An event
+// column is optional.
+var replayColumns = []string{"timestamp", "condition", "temperature", "humidity", "wind_speed"}
+
+// ReplayWeather plays back recorded weather, such as a real historical period or the
+// weather a player reported a bug under. The recording plays in real time from the
+// given start, each reading holding until the next, and starts over when it runs out.
+type ReplayWeather struct {
+	records []WeatherRecord
+	from    time.Time // time in the recording that plays at started
+	started time.Time
+	length  time.Duration // how long one play through the recording lasts
+}
+
+// NewReplayWeather loads a CSV or JSON replay file, chosen by its extension, and plays
+// it from the given RFC 3339 time in the recording, or from its start if from is empty
+func NewReplayWeather(path, from string, started time.Time) (*ReplayWeather, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no weather replay file given")
+	}
+
+	records, err := loadWeatherRecords(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("weather replay %s has no records", path)
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Timestamp.Before(records[j].Timestamp) })
+
+	replay := &ReplayWeather{
+		records: records,
+		from:    records[0].Timestamp,
+		started: started,
+	}
+
+	// The last reading lasts as long as the one before it did
+	if n := len(records); n > 1 {
+		last := records[n-1].Timestamp.Sub(records[n-2].Timestamp)
+		replay.length = records[n-1].Timestamp.Sub(records[0].Timestamp) + last
+	}
+
+	if from != "" {
+		replay.from, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weather replay start %q: %w", from, err)
+		}
+	}
+
+	return replay, nil
+}
+
+// Weather returns the recorded reading for the given time
+func (r *ReplayWeather) Weather(ctx context.Context, previous models.Weather, at time.Time) (models.Weather, error) {
+	record := r.recordAt(r.from.Add(at.Sub(r.started)))
+	return models.Weather{
+		Condition:   record.Condition,
+		Temperature: record.Temperature,
+		Humidity:    record.Humidity,
+		WindSpeed:   record.WindSpeed,
+		Event:       record.Event,
+	}, nil
+}
+
+// Ahead is true: the whole recording is known up front
+func (r *ReplayWeather) Ahead() bool {
+	return true
+}
+
+// recordAt returns the reading that holds at a time in the recording
+func (r *ReplayWeather) recordAt(at time.Time) WeatherRecord {
+	first := r.records[0].Timestamp
+	if r.length <= 0 || at.Before(first) {
+		return r.records[0]
+	}
+
+	// Start over once the recording runs out
+	at = first.Add(at.Sub(first) % r.length)
+
+	i := sort.Search(len(r.records), func(i int) bool { return r.records[i].Timestamp.After(at) })
+	return r.records[i-1]
+}
+
+// loadWeatherRecords reads the records of a replay file
+func loadWeatherRecords(path string) ([]WeatherRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open weather replay: %w", err)
+	}
+	defer file.Close()
+
+	var records []WeatherRecord
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.NewDecoder(file).Decode(&records); err != nil {
+			return nil, fmt.Errorf("failed to parse weather replay %s: %w", path, err)
+		}
+	case ".csv":
+		records, err = readWeatherCSV(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse weather replay %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("weather replay %s must be a .csv or .json file", path)
+	}
+
+	for i, record := range records {
+		if !record.Condition.Valid() {
+			return nil, fmt.Errorf("weather replay %s: record %d: unknown condition %q", path, i+1, record.Condition)
+		}
+		if record.Event != "" && !record.Event.Valid() {
+			return nil, fmt.Errorf("weather replay %s: record %d: unknown event %q", path, i+1, record.Event)
+		}
+	}
+	return records, nil
+}
+
+// readWeatherCSV reads replay records from CSV with a header row naming replayColumns
+func readWeatherCSV(r io.Reader) ([]WeatherRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("missing header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range replayColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing %s column", name)
+		}
+	}
+
+	var records []WeatherRecord
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var record WeatherRecord
+		record.Condition = models.WeatherCondition(row[columns["condition"]])
+		if record.Timestamp, err = time.Parse(time.RFC3339, row[columns["timestamp"]]); err != nil {
+			return nil, fmt.Errorf("line %d: invalid timestamp: %w", line, err)
+		}
+		if record.Temperature, err = strconv.ParseFloat(row[columns["temperature"]], 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid temperature: %w", line, err)
+		}
+		if record.Humidity, err = strconv.Atoi(row[columns["humidity"]]); err != nil {
+			return nil, fmt.Errorf("line %d: invalid humidity: %w", line, err)
+		}
+		if record.WindSpeed, err = strconv.ParseFloat(row[columns["wind_speed"]], 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid wind speed: %w", line, err)
+		}
+		if i, ok := columns["event"]; ok {
+			record.Event = models.WeatherEvent(strings.TrimSpace(row[i]))
+		}
+		records = append(records, record)
+	}
+}
@@ -0,0 +1,225 @@
+package game
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/my-garden/api/internal/config"
+	"github.com/my-garden/api/internal/models"
+)
+
+// writeReplay writes a replay file with the given name into a temporary directory
+func writeReplay(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func parseTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return at
+}
+
+func TestLoadWeatherRecords(t *testing.T) {
+	want := []WeatherRecord{
+		{Condition: models.WeatherSunny, Temperature: 24.5, Humidity: 40, WindSpeed: 10},
+		{Condition: models.WeatherStormy, Temperature: 19, Humidity: 90, WindSpeed: 65.5, Event: models.WeatherEventHail},
+	}
+	want[0].Timestamp = parseTime(t, "2024-06-01T12:00:00Z")
+	want[1].Timestamp = parseTime(t, "2024-06-01T13:00:00Z")
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		noEvents bool // the file has no event column
+	}{
+		{
+			name: "csv",
+			file: "replay.csv",
+			content: "timestamp,condition,temperature,humidity,wind_speed,event\n" +
+				"2024-06-01T12:00:00Z,sunny,24.5,40,10,\n" +
+				"2024-06-01T13:00:00Z,stormy,19,90,65.5,hail\n",
+		},
+		{
+			name: "csv with columns in another order and no event column",
+			file: "replay.CSV",
+			content: "Condition, Timestamp, Humidity, Wind_Speed, Temperature\n" +
+				"sunny, 2024-06-01T12:00:00Z, 40, 10, 24.5\n" +
+				"stormy, 2024-06-01T13:00:00Z, 90, 65.5, 19\n",
+			noEvents: true,
+		},
+		{
+			name: "json",
+			file: "replay.json",
+			content: `[
+				{"timestamp": "2024-06-01T12:00:00Z", "condition": "sunny", "temperature": 24.5, "humidity": 40, "wind_speed": 10},
+				{"timestamp": "2024-06-01T13:00:00Z", "condition": "stormy", "temperature": 19, "humidity": 90, "wind_speed": 65.5, "event": "hail"}
+			]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := loadWeatherRecords(writeReplay(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != len(want) {
+				t.Fatalf("got %d records, want %d", len(records), len(want))
+			}
+			for i := range want {
+				expected := want[i]
+				if tt.noEvents {
+					expected.Event = ""
+				}
+				if !records[i].Timestamp.Equal(expected.Timestamp) {
+					t.Errorf("record %d timestamp = %s, want %s", i, records[i].Timestamp, expected.Timestamp)
+				}
+				records[i].Timestamp = expected.Timestamp
+				if records[i] != expected {
+					t.Errorf("record %d = %+v, want %+v", i, records[i], expected)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadWeatherRecordsRejectsBadFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		err     string
+	}{
+		{
+			name:    "missing column",
+			file:    "replay.csv",
+			content: "timestamp,condition,temperature,humidity\n2024-06-01T12:00:00Z,sunny,24.5,40\n",
+			err:     "missing wind_speed column",
+		},
+		{
+			name:    "invalid timestamp",
+			file:    "replay.csv",
+			content: "timestamp,condition,temperature,humidity,wind_speed\nnoon,sunny,24.5,40,10\n",
+			err:     "line 2: invalid timestamp",
+		},
+		{
+			name:    "unknown condition",
+			file:    "replay.csv",
+			content: "timestamp,condition,temperature,humidity,wind_speed\n2024-06-01T12:00:00Z,drizzly,24.5,40,10\n",
+			err:     `unknown condition "drizzly"`,
+		},
+		{
+			name:    "unknown event",
+			file:    "replay.json",
+			content: `[{"timestamp": "2024-06-01T12:00:00Z", "condition": "sunny", "temperature": 24.5, "humidity": 40, "wind_speed": 10, "event": "meteor"}]`,
+			err:     `unknown event "meteor"`,
+		},
+		{
+			name:    "malformed json",
+			file:    "replay.json",
+			content: `[{"timestamp": "2024-06-01T12:00:00Z",`,
+			err:     "failed to parse weather replay",
+		},
+		{
+			name:    "unsupported extension",
+			file:    "replay.txt",
+			content: "sunny",
+			err:     "must be a .csv or .json file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadWeatherRecords(writeReplay(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error = %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestReplayWeatherWrapsAround(t *testing.T) {
+	// Three hourly readings, so one play through lasts three hours
+	path := writeReplay(t, "replay.csv", "timestamp,condition,temperature,humidity,wind_speed\n"+
+		"2024-06-01T13:00:00Z,cloudy,20,50,5\n"+
+		"2024-06-01T12:00:00Z,sunny,22,40,5\n"+
+		"2024-06-01T14:00:00Z,rainy,17,90,15\n")
+
+	started := parseTime(t, "2026-01-10T08:00:00Z")
+	replay, err := NewReplayWeather(path, "", started)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.length != 3*time.Hour {
+		t.Fatalf("length = %s, want 3h", replay.length)
+	}
+
+	tests := []struct {
+		after time.Duration
+		want  models.WeatherCondition
+	}{
+		{0, models.WeatherSunny},
+		{59 * time.Minute, models.WeatherSunny},
+		{time.Hour, models.WeatherCloudy},
+		{2*time.Hour + 30*time.Minute, models.WeatherRainy},
+		{3 * time.Hour, models.WeatherSunny},
+		{4*time.Hour + 15*time.Minute, models.WeatherCloudy},
+		{26 * time.Hour, models.WeatherRainy},
+		{-time.Hour, models.WeatherSunny},
+	}
+	for _, tt := range tests {
+		got, err := replay.Weather(context.Background(), defaultWeather, started.Add(tt.after))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Condition != tt.want {
+			t.Errorf("%s after the start: condition = %s, want %s", tt.after, got.Condition, tt.want)
+		}
+	}
+
+	// Starting part way through carries on from there
+	replay, err = NewReplayWeather(path, "2024-06-01T14:00:00Z", started)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for after, want := range map[time.Duration]models.WeatherCondition{
+		0:                models.WeatherRainy,
+		time.Hour:        models.WeatherSunny,
+		2 * time.Hour:    models.WeatherCloudy,
+		3*time.Hour + 1:  models.WeatherRainy,
+		30 * time.Minute: models.WeatherRainy,
+	} {
+		if got := replay.recordAt(replay.from.Add(after)).Condition; got != want {
+			t.Errorf("%s after 14:00: condition = %s, want %s", after, got, want)
+		}
+	}
+}
+
+func TestReplayStartFromConfig(t *testing.T) {
+	cfg := config.WeatherConfig{ReplayFile: "replay.csv", ReplayStart: "2026-01-10T08:00:00Z"}
+	started, err := replayStart(context.Background(), cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := parseTime(t, "2026-01-10T08:00:00Z"); !started.Equal(want) {
+		t.Errorf("started = %s, want %s", started, want)
+	}
+
+	cfg.ReplayStart = "last tuesday"
+	if _, err := replayStart(context.Background(), cfg, nil); err == nil {
+		t.Error("expected an error for an invalid start time")
+	}
+}